@@ -0,0 +1,168 @@
+// Package ast parses JSON5 documents into a concrete syntax tree that keeps everything json5.UnMarshal
+// throws away: comments, whitespace, the quote style of strings, number literals as written and trailing
+// commas. An unmodified tree prints back byte for byte identical to its source.
+//
+// Whitespace and comments are stored as Trivia in the node slot they precede, so the comments documenting
+// an object member are the Before trivia of that member.
+package ast
+
+import (
+	"strings"
+
+	"github.com/shoobyban/json5"
+)
+
+// Trivia is the whitespace and comments between two tokens, kept verbatim
+type Trivia string
+
+// Comments returns the comments in t, delimiters included
+func (t Trivia) Comments() []string {
+	var comments []string
+	for _, tok := range json5.Tokenize(string(t)) {
+		if tok.Type == json5.TOKEN_COMMENT {
+			comments = append(comments, tok.Value)
+		}
+	}
+	return comments
+}
+
+// Node is a JSON5 value: *Object, *Array, *String, *Number, *Bool or *Null
+type Node interface {
+	write(sb *strings.Builder)
+}
+
+// Document is a parsed JSON5 document
+type Document struct {
+	Before Trivia // before the root value, such as a header comment
+	Root   Node
+	After  Trivia // after the root value
+}
+
+// Object is an object with its members in source order
+type Object struct {
+	Members []*Member
+	End     Trivia // before the closing brace, after the last member and its trailing comma if any
+}
+
+// Member is a key and value pair of an object, with the trivia around its tokens
+type Member struct {
+	Before     Trivia // before the key, such as the comments documenting the member
+	Key        *String
+	AfterKey   Trivia // between the key and the colon
+	AfterColon Trivia // between the colon and the value
+	Value      Node
+	AfterValue Trivia // between the value and the comma or closing brace
+	Comma      bool   // whether a comma follows the value, which for the last member is a trailing comma
+}
+
+// Array is an array with its elements in source order
+type Array struct {
+	Elements []*Element
+	End      Trivia // before the closing bracket, after the last element and its trailing comma if any
+}
+
+// Element is an item of an array, with the trivia around it
+type Element struct {
+	Before Trivia // before the value
+	Value  Node
+	After  Trivia // between the value and the comma or closing bracket
+	Comma  bool   // whether a comma follows the value, which for the last element is a trailing comma
+}
+
+// String is a string value or an object key
+type String struct {
+	Raw   string // the literal as written, quotes and escape sequences included
+	Value string // the decoded string
+	Quote rune   // '"' or '\'', or 0 for an unquoted identifier
+}
+
+// Number is a number literal, such as 0x1F, .5 or +Infinity
+type Number struct {
+	Raw string // the literal as written
+}
+
+// Value returns the number as a json5.Number, for conversion
+func (n *Number) Value() json5.Number {
+	return json5.Number(n.Raw)
+}
+
+// Bool is true or false
+type Bool struct {
+	Value bool
+}
+
+// Null is null
+type Null struct{}
+
+// Bytes prints the document
+func (d *Document) Bytes() []byte {
+	return []byte(d.String())
+}
+
+// String prints the document
+func (d *Document) String() string {
+	var sb strings.Builder
+	sb.WriteString(string(d.Before))
+	d.Root.write(&sb)
+	sb.WriteString(string(d.After))
+	return sb.String()
+}
+
+// Print returns the source text of a node
+func Print(n Node) string {
+	var sb strings.Builder
+	n.write(&sb)
+	return sb.String()
+}
+
+func (o *Object) write(sb *strings.Builder) {
+	sb.WriteByte('{')
+	for _, m := range o.Members {
+		sb.WriteString(string(m.Before))
+		m.Key.write(sb)
+		sb.WriteString(string(m.AfterKey))
+		sb.WriteByte(':')
+		sb.WriteString(string(m.AfterColon))
+		m.Value.write(sb)
+		sb.WriteString(string(m.AfterValue))
+		if m.Comma {
+			sb.WriteByte(',')
+		}
+	}
+	sb.WriteString(string(o.End))
+	sb.WriteByte('}')
+}
+
+func (a *Array) write(sb *strings.Builder) {
+	sb.WriteByte('[')
+	for _, e := range a.Elements {
+		sb.WriteString(string(e.Before))
+		e.Value.write(sb)
+		sb.WriteString(string(e.After))
+		if e.Comma {
+			sb.WriteByte(',')
+		}
+	}
+	sb.WriteString(string(a.End))
+	sb.WriteByte(']')
+}
+
+func (s *String) write(sb *strings.Builder) {
+	sb.WriteString(s.Raw)
+}
+
+func (n *Number) write(sb *strings.Builder) {
+	sb.WriteString(n.Raw)
+}
+
+func (b *Bool) write(sb *strings.Builder) {
+	if b.Value {
+		sb.WriteString("true")
+	} else {
+		sb.WriteString("false")
+	}
+}
+
+func (*Null) write(sb *strings.Builder) {
+	sb.WriteString("null")
+}
@@ -0,0 +1,119 @@
+package ast
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/shoobyban/json5"
+	"github.com/stretchr/testify/assert"
+)
+
+const sample = `// Service configuration
+/* header */ {
+  // the name
+  name: 'svc', // inline
+  "port" /* after key */ : /* before value */ 0x1F90,
+  ratio: .50,
+  tags: [ 'a' , "b", /* last */ ],
+  empty: {},
+  nothing: null,
+  on: true,
+  Infinity: -Infinity,
+  // dangling comment
+}
+// trailer
+`
+
+func TestParseRoundTrip(t *testing.T) {
+	doc, err := Parse([]byte(sample))
+	assert.NoError(t, err)
+	assert.Equal(t, sample, doc.String())
+	assert.Equal(t, []byte(sample), doc.Bytes())
+}
+
+func TestParseTree(t *testing.T) {
+	doc, err := Parse([]byte(sample))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"// Service configuration", "/* header */"}, doc.Before.Comments())
+	assert.Equal(t, []string{"// trailer"}, doc.After.Comments())
+
+	obj := doc.Root.(*Object)
+	assert.Len(t, obj.Members, 8)
+	assert.Equal(t, []string{"// dangling comment"}, obj.End.Comments())
+
+	name := obj.Members[0]
+	assert.Equal(t, []string{"// the name"}, name.Before.Comments())
+	assert.Equal(t, &String{Raw: "name", Value: "name"}, name.Key)
+	assert.Equal(t, &String{Raw: "'svc'", Value: "svc", Quote: '\''}, name.Value)
+	assert.True(t, name.Comma)
+
+	port := obj.Members[1]
+	assert.Equal(t, []string{"// inline"}, port.Before.Comments())
+	assert.Equal(t, &String{Raw: `"port"`, Value: "port", Quote: '"'}, port.Key)
+	assert.Equal(t, Trivia(" /* after key */ "), port.AfterKey)
+	assert.Equal(t, Trivia(" /* before value */ "), port.AfterColon)
+	assert.Equal(t, json5.Number("0x1F90"), port.Value.(*Number).Value())
+
+	assert.Equal(t, &Number{Raw: ".50"}, obj.Members[2].Value)
+
+	tags := obj.Members[3].Value.(*Array)
+	assert.Len(t, tags.Elements, 2)
+	assert.Equal(t, Trivia(" "), tags.Elements[0].After)
+	assert.True(t, tags.Elements[1].Comma)
+	assert.Equal(t, []string{"/* last */"}, tags.End.Comments())
+
+	assert.Empty(t, obj.Members[4].Value.(*Object).Members)
+	assert.Equal(t, &Null{}, obj.Members[5].Value)
+	assert.Equal(t, &Bool{Value: true}, obj.Members[6].Value)
+	assert.Equal(t, "Infinity", obj.Members[7].Key.Value)
+	assert.Equal(t, "-Infinity", Print(obj.Members[7].Value))
+}
+
+func TestParseScalars(t *testing.T) {
+	for _, src := range []string{`1`, ` "x" `, "// c\nnull", `false`, `[]`, `{}`, "\uFEFF{a: 1}"} {
+		doc, err := Parse([]byte(src))
+		assert.NoError(t, err, src)
+		assert.Equal(t, src, doc.String(), src)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	_, err := Parse([]byte(`{a: 1,, b: 2}`))
+	assert.ErrorIs(t, err, json5.ErrUnexpectedToken)
+	var syntaxErr *json5.SyntaxError
+	assert.ErrorAs(t, err, &syntaxErr)
+
+	_, err = Parse([]byte(`{a: 1} // done` + "\n" + `]`))
+	assert.EqualError(t, err, "2:1: expected end of input but found ']'")
+
+	_, err = ParseWithOptions([]byte(`{a: 1, // no comments in JSON`+"\n}"), json5.ParseOptions{JSON: true})
+	assert.ErrorIs(t, err, json5.ErrJSON5Only)
+}
+
+// TestParseCorpus prints back every valid document of the json5-tests corpus unchanged
+func TestParseCorpus(t *testing.T) {
+	dir := filepath.Join("..", "testdata", "json5-tests")
+	count := 0
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		if ext := filepath.Ext(path); ext != ".json" && ext != ".json5" {
+			return nil
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		doc, err := Parse(src)
+		if assert.NoError(t, err, path) {
+			assert.Equal(t, string(src), doc.String(), path)
+		}
+		count++
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Greater(t, count, 50)
+}
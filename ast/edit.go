@@ -0,0 +1,460 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shoobyban/json5"
+	"github.com/shoobyban/json5/internal/jsonpath"
+)
+
+// Paths name the value to edit as in json5 errors: $.servers[0].host, $["odd key"], or without the leading $,
+// servers[0].host. Values are converted with json5.MarshalIndent, indented like the surrounding document,
+// unless they are already a Node.
+
+// Get returns the node at path
+func (d *Document) Get(path string) (Node, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	return d.find(segments)
+}
+
+// Set replaces the value at path, keeping the comments around it, or adds a member to an object if the
+// last key of path is missing. New members are appended after the existing ones, with their indentation.
+func (d *Document) Set(path string, value interface{}) error {
+	segments, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	if len(segments) == 0 {
+		node, err := d.node(value, "", d.Root)
+		if err != nil {
+			return err
+		}
+		d.Root = node
+		return nil
+	}
+
+	parent, err := d.find(segments[:len(segments)-1])
+	if err != nil {
+		return err
+	}
+	switch last := segments[len(segments)-1].(type) {
+	case string:
+		obj, ok := parent.(*Object)
+		if !ok {
+			return d.notA("object", segments[:len(segments)-1])
+		}
+		if i := obj.index(last); i >= 0 {
+			m := obj.Members[i]
+			m.Value, err = d.node(value, indentOf(m.Before), m.Value)
+			return err
+		}
+		// Convert first so that a failure leaves the document as it was
+		if _, err := d.node(value, "", nil); err != nil {
+			return err
+		}
+		m := &Member{Key: newKey(last, 0), AfterColon: " "}
+		appendSlot(obj.slots(), &obj.End, m.slot(), d.indentUnit())
+		if m.Value, err = d.node(value, indentOf(m.Before), nil); err != nil {
+			return err
+		}
+		obj.Members = append(obj.Members, m)
+	case int:
+		arr, ok := parent.(*Array)
+		if !ok {
+			return d.notA("array", segments[:len(segments)-1])
+		}
+		if last >= len(arr.Elements) {
+			return fmt.Errorf("ast: %s: index out of range", jsonpath.Format(segments))
+		}
+		e := arr.Elements[last]
+		e.Value, err = d.node(value, indentOf(e.Before), e.Value)
+		return err
+	}
+	return nil
+}
+
+// Delete removes the member or element at path, with the comments before it and on its line
+func (d *Document) Delete(path string) error {
+	segments, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	if len(segments) == 0 {
+		return fmt.Errorf("ast: cannot delete the root value")
+	}
+	if _, err := d.find(segments); err != nil {
+		return err
+	}
+
+	parent, _ := d.find(segments[:len(segments)-1])
+	switch last := segments[len(segments)-1].(type) {
+	case string:
+		obj := parent.(*Object)
+		i := obj.index(last)
+		removeSlot(obj.slots(), i, &obj.End)
+		obj.Members = append(obj.Members[:i], obj.Members[i+1:]...)
+	case int:
+		arr := parent.(*Array)
+		removeSlot(arr.slots(), last, &arr.End)
+		arr.Elements = append(arr.Elements[:last], arr.Elements[last+1:]...)
+	}
+	return nil
+}
+
+// Insert adds value to the array at path before the element at index, or after the last one if index is
+// the length of the array
+func (d *Document) Insert(path string, index int, value interface{}) error {
+	segments, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	node, err := d.find(segments)
+	if err != nil {
+		return err
+	}
+	arr, ok := node.(*Array)
+	if !ok {
+		return d.notA("array", segments)
+	}
+	if index < 0 || index > len(arr.Elements) {
+		return fmt.Errorf("ast: %s: index %d out of range", jsonpath.Format(segments), index)
+	}
+
+	if _, err := d.node(value, "", nil); err != nil {
+		return err
+	}
+	e := &Element{}
+	if index == len(arr.Elements) {
+		appendSlot(arr.slots(), &arr.End, e.slot(), d.indentUnit())
+	} else {
+		insertSlot(arr.slots(), index, e.slot())
+	}
+	if e.Value, err = d.node(value, indentOf(e.Before), nil); err != nil {
+		return err
+	}
+	arr.Elements = append(arr.Elements[:index], append([]*Element{e}, arr.Elements[index:]...)...)
+	return nil
+}
+
+// Rename changes the key of the member at path, keeping its quote style where the new key allows
+func (d *Document) Rename(path string, to string) error {
+	segments, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	var key string
+	var ok bool
+	if len(segments) > 0 {
+		key, ok = segments[len(segments)-1].(string)
+	}
+	if !ok {
+		return fmt.Errorf("ast: %s: not an object member", jsonpath.Format(segments))
+	}
+	if _, err := d.find(segments); err != nil {
+		return err
+	}
+
+	parent, _ := d.find(segments[:len(segments)-1])
+	obj := parent.(*Object)
+	if key == to {
+		return nil
+	}
+	if obj.index(to) >= 0 {
+		return fmt.Errorf("ast: %s: key '%s' already exists", jsonpath.Format(segments[:len(segments)-1]), to)
+	}
+	m := obj.Members[obj.index(key)]
+	m.Key = newKey(to, m.Key.Quote)
+	return nil
+}
+
+// find returns the node at the path given by segments
+func (d *Document) find(segments []interface{}) (Node, error) {
+	node := d.Root
+	for i, seg := range segments {
+		switch seg := seg.(type) {
+		case string:
+			obj, ok := node.(*Object)
+			if !ok {
+				return nil, d.notA("object", segments[:i])
+			}
+			j := obj.index(seg)
+			if j < 0 {
+				return nil, fmt.Errorf("ast: %s: no such key", jsonpath.Format(segments[:i+1]))
+			}
+			node = obj.Members[j].Value
+		case int:
+			arr, ok := node.(*Array)
+			if !ok {
+				return nil, d.notA("array", segments[:i])
+			}
+			if seg >= len(arr.Elements) {
+				return nil, fmt.Errorf("ast: %s: index out of range", jsonpath.Format(segments[:i+1]))
+			}
+			node = arr.Elements[seg].Value
+		}
+	}
+	return node, nil
+}
+
+// notA reports that the value at the path given by segments is not the container expected
+func (d *Document) notA(expected string, segments []interface{}) error {
+	return fmt.Errorf("ast: %s: not an %s", jsonpath.Format(segments), expected)
+}
+
+// node converts value into a node indented at indent; strings replacing a string keep its quotes
+func (d *Document) node(value interface{}, indent string, old Node) (Node, error) {
+	if n, ok := value.(Node); ok {
+		return n, nil
+	}
+	if s, ok := value.(string); ok {
+		if old, ok := old.(*String); ok && old.Quote == '\'' {
+			return &String{Raw: quoteString(s, '\''), Value: s, Quote: '\''}, nil
+		}
+	}
+
+	text, err := json5.MarshalIndent(value, d.indentUnit(), json5.MarshalOptions{SortKeys: true})
+	if err != nil {
+		return nil, err
+	}
+	doc, err := Parse([]byte(strings.ReplaceAll(text, "\n", "\n"+indent)))
+	if err != nil {
+		return nil, err
+	}
+	return doc.Root, nil
+}
+
+// indentUnit returns the indentation of the first member or element of the root, or two spaces
+func (d *Document) indentUnit() string {
+	var before Trivia
+	switch root := d.Root.(type) {
+	case *Object:
+		if len(root.Members) > 0 {
+			before = root.Members[0].Before
+		}
+	case *Array:
+		if len(root.Elements) > 0 {
+			before = root.Elements[0].Before
+		}
+	}
+	if unit := indentOf(before); unit != "" {
+		return unit
+	}
+	return "  "
+}
+
+// index returns the index of the member with key, the last one if the key is repeated, or -1
+func (o *Object) index(key string) int {
+	for i := len(o.Members) - 1; i >= 0; i-- {
+		if o.Members[i].Key.Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// slot is the trivia and comma around a member or an element, so that both are placed alike
+type slot struct {
+	before *Trivia
+	after  *Trivia // between the value and the comma or the closing delimiter
+	comma  *bool
+}
+
+func (m *Member) slot() slot {
+	return slot{before: &m.Before, after: &m.AfterValue, comma: &m.Comma}
+}
+
+func (e *Element) slot() slot {
+	return slot{before: &e.Before, after: &e.After, comma: &e.Comma}
+}
+
+func (o *Object) slots() []slot {
+	slots := make([]slot, len(o.Members))
+	for i, m := range o.Members {
+		slots[i] = m.slot()
+	}
+	return slots
+}
+
+func (a *Array) slots() []slot {
+	slots := make([]slot, len(a.Elements))
+	for i, e := range a.Elements {
+		slots[i] = e.slot()
+	}
+	return slots
+}
+
+// appendSlot places s after slots, on a line of its own if the last one is; end is the trivia before the
+// closing delimiter. Without a trailing comma that trivia is the after of the last slot, as parsed.
+func appendSlot(slots []slot, end *Trivia, s slot, unit string) {
+	if len(slots) == 0 {
+		if strings.Contains(string(*end), "\n") {
+			*s.before = "\n" + Trivia(indentOf(*end)+unit)
+		}
+		*s.after, *end = *end, ""
+		return
+	}
+
+	last := slots[len(slots)-1]
+	closing := end
+	if !*last.comma {
+		closing = last.after
+	}
+	head, tail := splitLine(*closing)
+	*closing = ""
+	if br := lineBreak(*last.before); br != "" {
+		*s.before = head + br
+		*end = tail
+	} else if hasLineComment(head) {
+		// A // comment ends the line: the comma goes before it and s starts the next line
+		*s.before = head + lineBreak(tail) + Trivia(unit)
+		*end = tail
+	} else {
+		*s.before = " "
+		*end = head + tail
+		// Comments after the last value stay with it
+		if closing == last.after {
+			comments := strings.TrimRight(string(head), " \t")
+			*last.after = Trivia(comments)
+			*end = head[len(comments):] + tail
+		}
+	}
+	*s.comma = *last.comma
+	*last.comma = true
+	if !*s.comma {
+		*s.after, *end = *end, ""
+	}
+}
+
+// insertSlot places s before slots[i], taking over the rest of the previous line
+func insertSlot(slots []slot, i int, s slot) {
+	next := slots[i]
+	head, tail := splitLine(*next.before)
+	if tail != "" {
+		*s.before = head + lineBreak(tail)
+		*next.before = tail
+	} else {
+		*s.before = *next.before
+		*next.before = " "
+	}
+	*s.comma = true
+}
+
+// removeSlot drops the trivia of slots[i]: the comments before it and on its line go with it, while the
+// rest of the previous line stays
+func removeSlot(slots []slot, i int, end *Trivia) {
+	s := slots[i]
+	head, _ := splitLine(*s.before)
+	if i+1 < len(slots) {
+		next := slots[i+1]
+		_, tail := splitLine(*next.before)
+		*next.before = head + tail
+		return
+	}
+
+	closing := *end
+	if !*s.comma {
+		closing = *s.after + *end
+	}
+	_, tail := splitLine(closing)
+	if strings.TrimSpace(string(head)) == "" {
+		// The space before the removed slot goes with it
+		head = ""
+	}
+	if i > 0 && !*s.comma {
+		prev := slots[i-1]
+		*prev.comma = false
+		*prev.after += head + tail
+		*end = ""
+		return
+	}
+	*end = head + tail
+}
+
+// splitLine splits t at its first line break outside a block comment: head is the rest of the line of the
+// previous token and tail starts with the line break
+func splitLine(t Trivia) (head, tail Trivia) {
+	s := string(t)
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\n':
+			return t[:i], t[i:]
+		case strings.HasPrefix(s[i:], "/*"):
+			if end := strings.Index(s[i+2:], "*/"); end >= 0 {
+				i += end + 3
+			} else {
+				return t, ""
+			}
+		}
+	}
+	return t, ""
+}
+
+// hasLineComment reports whether t holds a // comment
+func hasLineComment(t Trivia) bool {
+	for _, c := range t.Comments() {
+		if strings.HasPrefix(c, "//") {
+			return true
+		}
+	}
+	return false
+}
+
+// lineBreak returns a line break followed by the indentation of the last line of t, or "" if t is on one line
+func lineBreak(t Trivia) Trivia {
+	if !strings.Contains(string(t), "\n") {
+		return ""
+	}
+	return "\n" + Trivia(indentOf(t))
+}
+
+// indentOf returns the whitespace ending t after its last line break, or "" if t is on one line
+func indentOf(t Trivia) string {
+	i := strings.LastIndexByte(string(t), '\n')
+	if i < 0 {
+		return ""
+	}
+	rest := string(t[i+1:])
+	return rest[:len(rest)-len(strings.TrimLeft(rest, " \t"))]
+}
+
+// newKey returns the node of an object key, unquoted if quote is 0 and key is an identifier
+func newKey(key string, quote rune) *String {
+	if quote == 0 && jsonpath.IsIdentifier(key) {
+		return &String{Raw: key, Value: key}
+	}
+	if quote == 0 {
+		quote = '"'
+	}
+	return &String{Raw: quoteString(key, quote), Value: key, Quote: quote}
+}
+
+// quoteString writes s as a string literal in double or single quotes
+func quoteString(s string, quote rune) string {
+	raw, _ := json5.Marshal(s)
+	if quote == '"' {
+		return raw
+	}
+	var sb strings.Builder
+	sb.WriteByte('\'')
+	inner := raw[1 : len(raw)-1]
+	for i := 0; i < len(inner); i++ {
+		switch {
+		case inner[i] == '\\' && i+1 < len(inner) && inner[i+1] == '"':
+			sb.WriteByte('"')
+			i++
+		case inner[i] == '\\' && i+1 < len(inner):
+			sb.WriteString(inner[i : i+2])
+			i++
+		case inner[i] == '\'':
+			sb.WriteString(`\'`)
+		default:
+			sb.WriteByte(inner[i])
+		}
+	}
+	sb.WriteByte('\'')
+	return sb.String()
+}
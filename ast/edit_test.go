@@ -0,0 +1,362 @@
+package ast
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const config = `// Deploy configuration
+{
+  // bumped by the release job
+  version: '1.2.3', // keep in sync with the tag
+  name: "svc",
+  servers: [
+    'a.example.com', // primary
+    'b.example.com',
+  ],
+  debug: false
+}
+`
+
+func TestEditSet(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  string
+		value interface{}
+		want  string
+	}{
+		{
+			name:  "replace string keeps quotes and comments",
+			path:  "$.version",
+			value: "1.2.4",
+			want: `// Deploy configuration
+{
+  // bumped by the release job
+  version: '1.2.4', // keep in sync with the tag
+  name: "svc",
+  servers: [
+    'a.example.com', // primary
+    'b.example.com',
+  ],
+  debug: false
+}
+`,
+		},
+		{
+			name:  "replace array element without leading $",
+			path:  "servers[1]",
+			value: "c.example.com",
+			want: `// Deploy configuration
+{
+  // bumped by the release job
+  version: '1.2.3', // keep in sync with the tag
+  name: "svc",
+  servers: [
+    'a.example.com', // primary
+    'c.example.com',
+  ],
+  debug: false
+}
+`,
+		},
+		{
+			name:  "add member after the last one without trailing comma",
+			path:  "$.replicas",
+			value: 3,
+			want: `// Deploy configuration
+{
+  // bumped by the release job
+  version: '1.2.3', // keep in sync with the tag
+  name: "svc",
+  servers: [
+    'a.example.com', // primary
+    'b.example.com',
+  ],
+  debug: false,
+  replicas: 3
+}
+`,
+		},
+		{
+			name:  "add nested object indented like its siblings",
+			path:  "$.limits",
+			value: map[string]interface{}{"cpu": 2},
+			want: `// Deploy configuration
+{
+  // bumped by the release job
+  version: '1.2.3', // keep in sync with the tag
+  name: "svc",
+  servers: [
+    'a.example.com', // primary
+    'b.example.com',
+  ],
+  debug: false,
+  limits: {
+    cpu: 2,
+  }
+}
+`,
+		},
+		{
+			name:  "replace with a quoted key",
+			path:  `$["name"]`,
+			value: "api",
+			want: `// Deploy configuration
+{
+  // bumped by the release job
+  version: '1.2.3', // keep in sync with the tag
+  name: "api",
+  servers: [
+    'a.example.com', // primary
+    'b.example.com',
+  ],
+  debug: false
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse([]byte(config))
+			assert.NoError(t, err)
+			assert.NoError(t, doc.Set(tt.path, tt.value))
+			assert.Equal(t, tt.want, doc.String())
+			_, err = Parse(doc.Bytes())
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestEditSetInline(t *testing.T) {
+	doc, err := Parse([]byte(`{a: 1, b: [1, 2]}`))
+	assert.NoError(t, err)
+	assert.NoError(t, doc.Set("c", "it's"))
+	assert.NoError(t, doc.Set("$.b[0]", 0))
+	assert.Equal(t, `{a: 1, b: [0, 2], c: "it's"}`, doc.String())
+
+	doc, err = Parse([]byte(`{}`))
+	assert.NoError(t, err)
+	assert.NoError(t, doc.Set("$.a", true))
+	assert.Equal(t, `{a: true}`, doc.String())
+
+	doc, err = Parse([]byte("{\n  list: {\n  },\n}"))
+	assert.NoError(t, err)
+	assert.NoError(t, doc.Set("$.list['odd key']", nil))
+	assert.Equal(t, "{\n  list: {\n    \"odd key\": null\n  },\n}", doc.String())
+
+	// A comment after the last value stays with it
+	doc, err = Parse([]byte(`{a: 1 /* c */}`))
+	assert.NoError(t, err)
+	assert.NoError(t, doc.Set("b", 2))
+	assert.Equal(t, `{a: 1 /* c */, b: 2}`, doc.String())
+
+	doc, err = Parse([]byte(`{a: 1 // c
+}`))
+	assert.NoError(t, err)
+	assert.NoError(t, doc.Set("b", 2))
+	assert.Equal(t, "{a: 1, // c\n  b: 2\n}", doc.String())
+
+	doc, err = Parse([]byte("{a: 1, // c\n}"))
+	assert.NoError(t, err)
+	assert.NoError(t, doc.Set("b", 2))
+	assert.Equal(t, "{a: 1, // c\n  b: 2,\n}", doc.String())
+}
+
+func TestEditDelete(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+		want string
+	}{
+		{
+			name: "member with its comments",
+			path: "$.version",
+			want: `// Deploy configuration
+{
+  name: "svc",
+  servers: [
+    'a.example.com', // primary
+    'b.example.com',
+  ],
+  debug: false
+}
+`,
+		},
+		{
+			name: "last member drops the comma before it",
+			path: "$.debug",
+			want: `// Deploy configuration
+{
+  // bumped by the release job
+  version: '1.2.3', // keep in sync with the tag
+  name: "svc",
+  servers: [
+    'a.example.com', // primary
+    'b.example.com',
+  ]
+}
+`,
+		},
+		{
+			name: "element keeps the trailing comma",
+			path: "$.servers[1]",
+			want: `// Deploy configuration
+{
+  // bumped by the release job
+  version: '1.2.3', // keep in sync with the tag
+  name: "svc",
+  servers: [
+    'a.example.com', // primary
+  ],
+  debug: false
+}
+`,
+		},
+		{
+			name: "member after an inline comment keeps it",
+			path: "$.name",
+			want: `// Deploy configuration
+{
+  // bumped by the release job
+  version: '1.2.3', // keep in sync with the tag
+  servers: [
+    'a.example.com', // primary
+    'b.example.com',
+  ],
+  debug: false
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse([]byte(config))
+			assert.NoError(t, err)
+			assert.NoError(t, doc.Delete(tt.path))
+			assert.Equal(t, tt.want, doc.String())
+			_, err = Parse(doc.Bytes())
+			assert.NoError(t, err)
+		})
+	}
+
+	doc, err := Parse([]byte(`{a: 1, b: 2, c: [3]}`))
+	assert.NoError(t, err)
+	assert.NoError(t, doc.Delete("b"))
+	assert.NoError(t, doc.Delete("c[0]"))
+	assert.Equal(t, `{a: 1, c: []}`, doc.String())
+	assert.NoError(t, doc.Delete("c"))
+	assert.NoError(t, doc.Delete("a"))
+	assert.Equal(t, `{}`, doc.String())
+
+	doc, err = Parse([]byte(`{a: 1, b: 2, c: 3}`))
+	assert.NoError(t, err)
+	assert.NoError(t, doc.Delete("c"))
+	assert.Equal(t, `{a: 1, b: 2}`, doc.String())
+
+	doc, err = Parse([]byte(`[1, 2,]`))
+	assert.NoError(t, err)
+	assert.NoError(t, doc.Delete("[1]"))
+	assert.Equal(t, `[1,]`, doc.String())
+}
+
+func TestEditInsert(t *testing.T) {
+	doc, err := Parse([]byte(config))
+	assert.NoError(t, err)
+	assert.NoError(t, doc.Insert("$.servers", 0, "z.example.com"))
+	assert.NoError(t, doc.Insert("$.servers", 2, "y.example.com"))
+	assert.NoError(t, doc.Insert("$.servers", 4, "x.example.com"))
+	assert.Equal(t, `// Deploy configuration
+{
+  // bumped by the release job
+  version: '1.2.3', // keep in sync with the tag
+  name: "svc",
+  servers: [
+    "z.example.com",
+    'a.example.com', // primary
+    "y.example.com",
+    'b.example.com',
+    "x.example.com",
+  ],
+  debug: false
+}
+`, doc.String())
+
+	doc, err = Parse([]byte(`[1, 2]`))
+	assert.NoError(t, err)
+	assert.NoError(t, doc.Insert("$", 0, 0))
+	assert.NoError(t, doc.Insert("$", 3, 3))
+	assert.Equal(t, `[0, 1, 2, 3]`, doc.String())
+
+	doc, err = Parse([]byte(`[]`))
+	assert.NoError(t, err)
+	assert.NoError(t, doc.Insert("$", 0, "a"))
+	assert.Equal(t, `["a"]`, doc.String())
+
+	doc, err = Parse([]byte(`[1 /* c */]`))
+	assert.NoError(t, err)
+	assert.NoError(t, doc.Insert("$", 1, 2))
+	assert.Equal(t, `[1 /* c */, 2]`, doc.String())
+
+	doc, err = Parse([]byte("[1, 2 // x\n]"))
+	assert.NoError(t, err)
+	assert.NoError(t, doc.Insert("$", 2, 3))
+	assert.Equal(t, "[1, 2, // x\n  3\n]", doc.String())
+	_, err = Parse(doc.Bytes())
+	assert.NoError(t, err)
+}
+
+func TestEditRename(t *testing.T) {
+	doc, err := Parse([]byte(`{a: 1, 'b': 2, "c": 3}`))
+	assert.NoError(t, err)
+	assert.NoError(t, doc.Rename("a", "alpha"))
+	assert.NoError(t, doc.Rename("b", "beta"))
+	assert.NoError(t, doc.Rename("c", "it's"))
+	assert.NoError(t, doc.Rename("alpha", "odd key"))
+	assert.Equal(t, `{"odd key": 1, 'beta': 2, "it's": 3}`, doc.String())
+
+	node, err := doc.Get(`$["odd key"]`)
+	assert.NoError(t, err)
+	assert.Equal(t, &Number{Raw: "1"}, node)
+
+	// Keywords are valid unquoted keys
+	doc, err = Parse([]byte(`{a: 1, b: 2, c: 3}`))
+	assert.NoError(t, err)
+	assert.NoError(t, doc.Rename("a", "true"))
+	assert.NoError(t, doc.Rename("b", "false"))
+	assert.NoError(t, doc.Rename("c", "null"))
+	assert.Equal(t, `{true: 1, false: 2, null: 3}`, doc.String())
+	_, err = Parse(doc.Bytes())
+	assert.NoError(t, err)
+}
+
+func TestEditErrors(t *testing.T) {
+	doc, err := Parse([]byte(config))
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"missing parent", doc.Set("$.a.b", 1), "ast: $.a: no such key"},
+		{"index out of range", doc.Set("$.servers[2]", 1), "ast: $.servers[2]: index out of range"},
+		{"not an object", doc.Set("$.servers.x", 1), "ast: $.servers: not an object"},
+		{"not an array", doc.Insert("$.name", 0, 1), "ast: $.name: not an array"},
+		{"insert out of range", doc.Insert("$.servers", 3, 1), "ast: $.servers: index 3 out of range"},
+		{"delete root", doc.Delete("$"), "ast: cannot delete the root value"},
+		{"delete missing", doc.Delete("$.nope"), "ast: $.nope: no such key"},
+		{"rename element", doc.Rename("$.servers[0]", "x"), "ast: $.servers[0]: not an object member"},
+		{"rename to existing", doc.Rename("$.name", "debug"), "ast: $: key 'debug' already exists"},
+		{"bad path", doc.Set("$.servers[x]", 1), `ast: invalid path "$.servers[x]": bad index "x"`},
+		{"unmarshalable", doc.Set("$.new", make(chan int)), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if assert.Error(t, tt.err) && tt.want != "" {
+				assert.EqualError(t, tt.err, tt.want)
+			}
+		})
+	}
+	assert.Equal(t, config, doc.String())
+}
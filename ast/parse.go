@@ -0,0 +1,125 @@
+package ast
+
+import (
+	"github.com/shoobyban/json5"
+)
+
+// Parse parses a JSON5 document into a syntax tree. The syntax accepted and the errors returned are those
+// of json5.UnMarshal.
+func Parse(src []byte) (*Document, error) {
+	return ParseWithOptions(src, json5.ParseOptions{})
+}
+
+// ParseWithOptions is Parse with the syntax accepted controlled by opts, as in json5.UnMarshalWithOptions
+func ParseWithOptions(src []byte, opts json5.ParseOptions) (*Document, error) {
+	// The json5 parser checks the document, trailing data included, so the tree is built from
+	// well-formed tokens only
+	opts.AllowMultipleValues = false
+	tokens, err := json5.ParseTokens(string(src), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{src: string(src)}
+	for _, tok := range tokens {
+		if tok.Type != json5.TOKEN_COMMENT {
+			p.tokens = append(p.tokens, tok)
+		}
+	}
+
+	doc := &Document{}
+	tok, before := p.next()
+	doc.Before = before
+	doc.Root = p.value(tok)
+	_, doc.After = p.next()
+	return doc, nil
+}
+
+// parser builds a syntax tree from the tokens of a well-formed document
+type parser struct {
+	src    string
+	tokens []json5.Token // without comments, which are part of the trivia, and ending with TOKEN_EOF
+	i      int           // index of the next token
+	end    int           // offset just past the last token consumed
+}
+
+// next consumes the next token, returning it with the trivia before it
+func (p *parser) next() (json5.Token, Trivia) {
+	tok := p.tokens[p.i]
+	if tok.Type != json5.TOKEN_EOF {
+		p.i++
+	}
+	trivia := Trivia(p.src[p.end:tok.Start.Offset])
+	p.end = tok.End.Offset
+	return tok, trivia
+}
+
+// value builds the node of the value starting with tok
+func (p *parser) value(tok json5.Token) Node {
+	switch tok.Type {
+	case json5.TOKEN_LBRACE:
+		return p.object()
+	case json5.TOKEN_LBRACKET:
+		return p.array()
+	case json5.TOKEN_NUMBER:
+		return &Number{Raw: p.raw(tok)}
+	case json5.TOKEN_TRUE:
+		return &Bool{Value: true}
+	case json5.TOKEN_FALSE:
+		return &Bool{Value: false}
+	case json5.TOKEN_NULL:
+		return &Null{}
+	}
+	return p.string(tok)
+}
+
+// string builds the node of a string value or key; keys can also be the identifiers Infinity and NaN
+func (p *parser) string(tok json5.Token) *String {
+	return &String{Raw: p.raw(tok), Value: tok.Value, Quote: tok.Quote}
+}
+
+// raw returns the source text of tok
+func (p *parser) raw(tok json5.Token) string {
+	return p.src[tok.Start.Offset:tok.End.Offset]
+}
+
+// object builds an object whose opening brace has been consumed
+func (p *parser) object() *Object {
+	obj := &Object{}
+	for {
+		tok, before := p.next()
+		if tok.Type == json5.TOKEN_RBRACE {
+			obj.End = before
+			return obj
+		}
+		m := &Member{Before: before, Key: p.string(tok)}
+		_, m.AfterKey = p.next() // colon
+		tok, m.AfterColon = p.next()
+		m.Value = p.value(tok)
+		tok, m.AfterValue = p.next()
+		obj.Members = append(obj.Members, m)
+		if tok.Type == json5.TOKEN_RBRACE {
+			return obj
+		}
+		m.Comma = true
+	}
+}
+
+// array builds an array whose opening bracket has been consumed
+func (p *parser) array() *Array {
+	arr := &Array{}
+	for {
+		tok, before := p.next()
+		if tok.Type == json5.TOKEN_RBRACKET {
+			arr.End = before
+			return arr
+		}
+		e := &Element{Before: before, Value: p.value(tok)}
+		tok, e.After = p.next()
+		arr.Elements = append(arr.Elements, e)
+		if tok.Type == json5.TOKEN_RBRACKET {
+			return arr
+		}
+		e.Comma = true
+	}
+}
@@ -0,0 +1,87 @@
+package ast
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// parsePath splits a path such as $.servers[0].host or $["odd key"] into keys (string) and indices (int).
+// The leading $ is optional, so servers[0].host is the same path.
+func parsePath(path string) ([]interface{}, error) {
+	s := strings.TrimPrefix(path, "$")
+	if s != path || strings.HasPrefix(s, ".") || strings.HasPrefix(s, "[") {
+		// Rooted path
+	} else if s != "" {
+		s = "." + s
+	}
+
+	var segments []interface{}
+	for s != "" {
+		switch s[0] {
+		case '.':
+			end := strings.IndexAny(s[1:], ".[")
+			if end < 0 {
+				end = len(s) - 1
+			}
+			key := s[1 : end+1]
+			if key == "" {
+				return nil, fmt.Errorf("ast: invalid path %q: empty key", path)
+			}
+			segments = append(segments, key)
+			s = s[end+1:]
+		case '[':
+			end := closingBracket(s)
+			if end < 0 {
+				return nil, fmt.Errorf("ast: invalid path %q: unterminated [", path)
+			}
+			inner := s[1:end]
+			if strings.HasPrefix(inner, `"`) || strings.HasPrefix(inner, `'`) {
+				key, err := unquoteKey(inner)
+				if err != nil {
+					return nil, fmt.Errorf("ast: invalid path %q: %v", path, err)
+				}
+				segments = append(segments, key)
+			} else {
+				index, err := strconv.Atoi(inner)
+				if err != nil || index < 0 {
+					return nil, fmt.Errorf("ast: invalid path %q: bad index %q", path, inner)
+				}
+				segments = append(segments, index)
+			}
+			s = s[end+1:]
+		default:
+			return nil, fmt.Errorf("ast: invalid path %q", path)
+		}
+	}
+	return segments, nil
+}
+
+// closingBracket returns the index of the ] closing the [ that starts s, skipping quoted keys, or -1
+func closingBracket(s string) int {
+	var quote byte
+	for i := 1; i < len(s); i++ {
+		switch {
+		case quote != 0 && s[i] == '\\':
+			i++
+		case quote != 0 && s[i] == quote:
+			quote = 0
+		case quote == 0 && (s[i] == '"' || s[i] == '\''):
+			quote = s[i]
+		case quote == 0 && s[i] == ']':
+			return i
+		}
+	}
+	return -1
+}
+
+// unquoteKey decodes a quoted key of a path, in single or double quotes
+func unquoteKey(s string) (string, error) {
+	if len(s) < 2 || s[len(s)-1] != s[0] {
+		return "", fmt.Errorf("bad key %s", s)
+	}
+	if s[0] == '\'' {
+		s = `"` + strings.ReplaceAll(strings.ReplaceAll(s[1:len(s)-1], `\'`, `'`), `"`, `\"`) + `"`
+	}
+	return strconv.Unquote(s)
+}
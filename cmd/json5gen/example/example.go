@@ -0,0 +1,67 @@
+// Package example shows the codecs generated by json5gen; example_json5.go is the generated file.
+package example
+
+import (
+	"fmt"
+	"time"
+)
+
+//go:generate go run github.com/shoobyban/json5/cmd/json5gen example.go
+
+// Mode is written as its underlying string
+type Mode string
+
+// Level is written as a name, through its own MarshalText method
+type Level int
+
+// levelNames are the names of the levels, in order
+var levelNames = []string{"debug", "info"}
+
+// MarshalText writes the name of the level
+func (l Level) MarshalText() ([]byte, error) {
+	return []byte(levelNames[l]), nil
+}
+
+// UnmarshalText reads the name of a level
+func (l *Level) UnmarshalText(text []byte) error {
+	for i, name := range levelNames {
+		if name == string(text) {
+			*l = Level(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown level %q", text)
+}
+
+// Meta is embedded in Config
+type Meta struct {
+	Version int    `json5:"version"`
+	Owner   string `json5:"owner,omitempty"`
+}
+
+// Config is a typical configuration file
+type Config struct {
+	Meta
+	Name     string             `json5:"name"`
+	Mode     Mode               `json5:"mode"`
+	Level    Level              `json5:"level"`
+	Port     uint16             `json5:"port"`
+	Ratio    float32            `json5:"ratio,omitempty"`
+	Debug    bool               `json:"debug"`
+	Tags     []string           `json5:"tags"`
+	Limits   map[string]int64   `json5:"limits,omitempty"`
+	Servers  []Server           `json5:"servers"`
+	Primary  *Server            `json5:"primary"`
+	ByName   map[string]*Server `json5:"byName,omitempty"`
+	Timeout  time.Duration      `json5:"timeout"`
+	Extra    interface{}        `json5:"extra,omitempty"`
+	Matrix   [][]float64        `json5:"matrix,omitempty"`
+	Internal string             `json5:"-"`
+	hidden   int
+}
+
+// Server is referenced by Config
+type Server struct {
+	Host string `json5:"host"`
+	Port int    `json5:"port"`
+}
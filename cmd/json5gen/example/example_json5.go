@@ -0,0 +1,460 @@
+// Code generated by json5gen; DO NOT EDIT.
+
+package example
+
+import (
+	"time"
+
+	"github.com/shoobyban/json5"
+)
+
+// MarshalJSON5 implements json5.Marshaler
+func (v Meta) MarshalJSON5() ([]byte, error) {
+	w := json5.NewWriter()
+	v.WriteJSON5(w)
+	return w.Bytes(), w.Err()
+}
+
+// WriteJSON5 writes v as a JSON5 object to w
+func (v Meta) WriteJSON5(w *json5.Writer) {
+	w.BeginObject()
+	w.Key("version")
+	w.Int(int64(v.Version))
+	if len(v.Owner) != 0 {
+		w.Key("owner")
+		w.String(string(v.Owner))
+	}
+	w.EndObject()
+}
+
+// UnmarshalJSON5 implements json5.Unmarshaler
+func (v *Meta) UnmarshalJSON5(data []byte) error {
+	r := json5.NewReader(data)
+	if err := v.ReadJSON5(r); err != nil {
+		return err
+	}
+	return r.End()
+}
+
+// ReadJSON5 reads v from a JSON5 object in r; keys match exactly or else ignoring case, unknown keys are skipped
+func (v *Meta) ReadJSON5(r *json5.Reader) error {
+	return r.ReadObject(func(key string) error {
+		switch r.MatchKey(key, "version", "owner") {
+		case "version":
+			if !r.ReadNull() {
+				n1, err := r.ReadInt(0)
+				if err != nil {
+					return err
+				}
+				v.Version = int(n1)
+			}
+			return nil
+		case "owner":
+			if !r.ReadNull() {
+				n2, err := r.ReadString()
+				if err != nil {
+					return err
+				}
+				v.Owner = string(n2)
+			}
+			return nil
+		}
+		return r.Skip()
+	})
+}
+
+// MarshalJSON5 implements json5.Marshaler
+func (v Config) MarshalJSON5() ([]byte, error) {
+	w := json5.NewWriter()
+	v.WriteJSON5(w)
+	return w.Bytes(), w.Err()
+}
+
+// WriteJSON5 writes v as a JSON5 object to w
+func (v Config) WriteJSON5(w *json5.Writer) {
+	w.BeginObject()
+	w.Key("version")
+	w.Int(int64(v.Meta.Version))
+	if len(v.Meta.Owner) != 0 {
+		w.Key("owner")
+		w.String(string(v.Meta.Owner))
+	}
+	w.Key("name")
+	w.String(string(v.Name))
+	w.Key("mode")
+	w.String(string(v.Mode))
+	w.Key("level")
+	w.Value(v.Level)
+	w.Key("port")
+	w.Uint(uint64(v.Port))
+	if v.Ratio != 0 {
+		w.Key("ratio")
+		w.Float(float64(v.Ratio), 32)
+	}
+	w.Key("debug")
+	w.Bool(bool(v.Debug))
+	w.Key("tags")
+	if v.Tags == nil {
+		w.Null()
+	} else {
+		w.BeginArray()
+		for _, item3 := range v.Tags {
+			w.String(string(item3))
+		}
+		w.EndArray()
+	}
+	if len(v.Limits) != 0 {
+		w.Key("limits")
+		keys4 := make([]string, 0, len(v.Limits))
+		for key5 := range v.Limits {
+			keys4 = append(keys4, key5)
+		}
+		w.BeginObject()
+		for _, key5 := range w.SortKeys(keys4) {
+			w.Key(key5)
+			w.Int(int64(v.Limits[key5]))
+		}
+		w.EndObject()
+	}
+	w.Key("servers")
+	if v.Servers == nil {
+		w.Null()
+	} else {
+		w.BeginArray()
+		for _, item6 := range v.Servers {
+			item6.WriteJSON5(w)
+		}
+		w.EndArray()
+	}
+	w.Key("primary")
+	if v.Primary == nil {
+		w.Null()
+	} else {
+		v.Primary.WriteJSON5(w)
+	}
+	if len(v.ByName) != 0 {
+		w.Key("byName")
+		keys7 := make([]string, 0, len(v.ByName))
+		for key8 := range v.ByName {
+			keys7 = append(keys7, key8)
+		}
+		w.BeginObject()
+		for _, key8 := range w.SortKeys(keys7) {
+			w.Key(key8)
+			if v.ByName[key8] == nil {
+				w.Null()
+			} else {
+				v.ByName[key8].WriteJSON5(w)
+			}
+		}
+		w.EndObject()
+	}
+	w.Key("timeout")
+	w.Int(int64(v.Timeout))
+	if v.Extra != nil {
+		w.Key("extra")
+		w.Value(v.Extra)
+	}
+	if len(v.Matrix) != 0 {
+		w.Key("matrix")
+		w.BeginArray()
+		for _, item9 := range v.Matrix {
+			if item9 == nil {
+				w.Null()
+			} else {
+				w.BeginArray()
+				for _, item10 := range item9 {
+					w.Float(float64(item10), 64)
+				}
+				w.EndArray()
+			}
+		}
+		w.EndArray()
+	}
+	w.EndObject()
+}
+
+// UnmarshalJSON5 implements json5.Unmarshaler
+func (v *Config) UnmarshalJSON5(data []byte) error {
+	r := json5.NewReader(data)
+	if err := v.ReadJSON5(r); err != nil {
+		return err
+	}
+	return r.End()
+}
+
+// ReadJSON5 reads v from a JSON5 object in r; keys match exactly or else ignoring case, unknown keys are skipped
+func (v *Config) ReadJSON5(r *json5.Reader) error {
+	return r.ReadObject(func(key string) error {
+		switch r.MatchKey(key, "version", "owner", "name", "mode", "level", "port", "ratio", "debug", "tags", "limits", "servers", "primary", "byName", "timeout", "extra", "matrix") {
+		case "version":
+			if !r.ReadNull() {
+				n11, err := r.ReadInt(0)
+				if err != nil {
+					return err
+				}
+				v.Meta.Version = int(n11)
+			}
+			return nil
+		case "owner":
+			if !r.ReadNull() {
+				n12, err := r.ReadString()
+				if err != nil {
+					return err
+				}
+				v.Meta.Owner = string(n12)
+			}
+			return nil
+		case "name":
+			if !r.ReadNull() {
+				n13, err := r.ReadString()
+				if err != nil {
+					return err
+				}
+				v.Name = string(n13)
+			}
+			return nil
+		case "mode":
+			if !r.ReadNull() {
+				n14, err := r.ReadString()
+				if err != nil {
+					return err
+				}
+				v.Mode = Mode(n14)
+			}
+			return nil
+		case "level":
+			if err := r.Decode(&v.Level); err != nil {
+				return err
+			}
+			return nil
+		case "port":
+			if !r.ReadNull() {
+				n15, err := r.ReadUint(16)
+				if err != nil {
+					return err
+				}
+				v.Port = uint16(n15)
+			}
+			return nil
+		case "ratio":
+			if !r.ReadNull() {
+				n16, err := r.ReadFloat(32)
+				if err != nil {
+					return err
+				}
+				v.Ratio = float32(n16)
+			}
+			return nil
+		case "debug":
+			if !r.ReadNull() {
+				n17, err := r.ReadBool()
+				if err != nil {
+					return err
+				}
+				v.Debug = bool(n17)
+			}
+			return nil
+		case "tags":
+			if r.ReadNull() {
+				v.Tags = nil
+			} else {
+				slice18 := []string{}
+				if err := r.ReadArray(func(int) error {
+					var item19 string
+					if !r.ReadNull() {
+						n20, err := r.ReadString()
+						if err != nil {
+							return err
+						}
+						item19 = string(n20)
+					}
+					slice18 = append(slice18, item19)
+					return nil
+				}); err != nil {
+					return err
+				}
+				v.Tags = slice18
+			}
+			return nil
+		case "limits":
+			if r.ReadNull() {
+				v.Limits = nil
+			} else {
+				m21 := map[string]int64{}
+				if err := r.ReadObject(func(key string) error {
+					var item22 int64
+					if !r.ReadNull() {
+						n23, err := r.ReadInt(64)
+						if err != nil {
+							return err
+						}
+						item22 = int64(n23)
+					}
+					m21[key] = item22
+					return nil
+				}); err != nil {
+					return err
+				}
+				v.Limits = m21
+			}
+			return nil
+		case "servers":
+			if r.ReadNull() {
+				v.Servers = nil
+			} else {
+				slice24 := []Server{}
+				if err := r.ReadArray(func(int) error {
+					var item25 Server
+					if !r.ReadNull() {
+						if err := item25.ReadJSON5(r); err != nil {
+							return err
+						}
+					}
+					slice24 = append(slice24, item25)
+					return nil
+				}); err != nil {
+					return err
+				}
+				v.Servers = slice24
+			}
+			return nil
+		case "primary":
+			if r.ReadNull() {
+				v.Primary = nil
+			} else {
+				if v.Primary == nil {
+					v.Primary = new(Server)
+				}
+				if err := v.Primary.ReadJSON5(r); err != nil {
+					return err
+				}
+			}
+			return nil
+		case "byName":
+			if r.ReadNull() {
+				v.ByName = nil
+			} else {
+				m26 := map[string]*Server{}
+				if err := r.ReadObject(func(key string) error {
+					var item27 *Server
+					if !r.ReadNull() {
+						item27 = new(Server)
+						if err := item27.ReadJSON5(r); err != nil {
+							return err
+						}
+					}
+					m26[key] = item27
+					return nil
+				}); err != nil {
+					return err
+				}
+				v.ByName = m26
+			}
+			return nil
+		case "timeout":
+			if !r.ReadNull() {
+				n28, err := r.ReadInt(64)
+				if err != nil {
+					return err
+				}
+				v.Timeout = time.Duration(n28)
+			}
+			return nil
+		case "extra":
+			n29, err := r.ReadValue()
+			if err != nil {
+				return err
+			}
+			v.Extra = n29
+			return nil
+		case "matrix":
+			if r.ReadNull() {
+				v.Matrix = nil
+			} else {
+				slice30 := [][]float64{}
+				if err := r.ReadArray(func(int) error {
+					var item31 []float64
+					if !r.ReadNull() {
+						slice32 := []float64{}
+						if err := r.ReadArray(func(int) error {
+							var item33 float64
+							if !r.ReadNull() {
+								n34, err := r.ReadFloat(64)
+								if err != nil {
+									return err
+								}
+								item33 = float64(n34)
+							}
+							slice32 = append(slice32, item33)
+							return nil
+						}); err != nil {
+							return err
+						}
+						item31 = slice32
+					}
+					slice30 = append(slice30, item31)
+					return nil
+				}); err != nil {
+					return err
+				}
+				v.Matrix = slice30
+			}
+			return nil
+		}
+		return r.Skip()
+	})
+}
+
+// MarshalJSON5 implements json5.Marshaler
+func (v Server) MarshalJSON5() ([]byte, error) {
+	w := json5.NewWriter()
+	v.WriteJSON5(w)
+	return w.Bytes(), w.Err()
+}
+
+// WriteJSON5 writes v as a JSON5 object to w
+func (v Server) WriteJSON5(w *json5.Writer) {
+	w.BeginObject()
+	w.Key("host")
+	w.String(string(v.Host))
+	w.Key("port")
+	w.Int(int64(v.Port))
+	w.EndObject()
+}
+
+// UnmarshalJSON5 implements json5.Unmarshaler
+func (v *Server) UnmarshalJSON5(data []byte) error {
+	r := json5.NewReader(data)
+	if err := v.ReadJSON5(r); err != nil {
+		return err
+	}
+	return r.End()
+}
+
+// ReadJSON5 reads v from a JSON5 object in r; keys match exactly or else ignoring case, unknown keys are skipped
+func (v *Server) ReadJSON5(r *json5.Reader) error {
+	return r.ReadObject(func(key string) error {
+		switch r.MatchKey(key, "host", "port") {
+		case "host":
+			if !r.ReadNull() {
+				n35, err := r.ReadString()
+				if err != nil {
+					return err
+				}
+				v.Host = string(n35)
+			}
+			return nil
+		case "port":
+			if !r.ReadNull() {
+				n36, err := r.ReadInt(0)
+				if err != nil {
+					return err
+				}
+				v.Port = int(n36)
+			}
+			return nil
+		}
+		return r.Skip()
+	})
+}
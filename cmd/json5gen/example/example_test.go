@@ -0,0 +1,120 @@
+package example
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shoobyban/json5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoundTrip(t *testing.T) {
+	config := Config{
+		Meta:    Meta{Version: 2},
+		Name:    "svc",
+		Mode:    "fast",
+		Level:   1,
+		Port:    8080,
+		Ratio:   0.25,
+		Debug:   true,
+		Tags:    []string{"a", "b"},
+		Limits:  map[string]int64{"b": 2, "a": 1},
+		Servers: []Server{{Host: "x", Port: 1}},
+		Primary: &Server{Host: "y", Port: 2},
+		ByName:  map[string]*Server{"z": nil},
+		Timeout: time.Second,
+		Extra:   []interface{}{1, "two"},
+		Matrix:  [][]float64{{1.5}, nil},
+	}
+	data, err := json5.MarshalIndent(config, "  ")
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+  version: 2,
+  name: "svc",
+  mode: "fast",
+  level: "info",
+  port: 8080,
+  ratio: 0.25,
+  debug: true,
+  tags: [
+    "a", 
+    "b"
+  ],
+  limits: {
+    a: 1,
+    b: 2,
+  },
+  servers: [
+    {
+      host: "x",
+      port: 1,
+    }
+  ],
+  primary: {
+    host: "y",
+    port: 2,
+  },
+  byName: {
+    z: null,
+  },
+  timeout: 1000000000,
+  extra: [
+    1, 
+    "two"
+  ],
+  matrix: [
+    [
+      1.5
+    ], 
+    null
+  ],
+}`, data)
+
+	var decoded Config
+	assert.NoError(t, json5.Unmarshal([]byte(data), &decoded))
+	assert.Equal(t, config, decoded)
+}
+
+func TestUnmarshal(t *testing.T) {
+	var config Config
+	err := json5.Unmarshal([]byte(`{
+		// unknown keys are skipped
+		unknown: {a: [1, 2]},
+		name: 'svc',
+		primary: null,
+		tags: null,
+		servers: [{host: 'x'}],
+	}`), &config)
+	assert.NoError(t, err)
+	assert.Equal(t, Config{Name: "svc", Servers: []Server{{Host: "x"}}}, config)
+}
+
+func TestUnmarshalKeysIgnoringCase(t *testing.T) {
+	var config Config
+	assert.NoError(t, json5.Unmarshal([]byte(`{NAME: 'svc', ByName: {a: {Host: 'x'}}}`), &config))
+	assert.Equal(t, Config{Name: "svc", ByName: map[string]*Server{"a": {Host: "x"}}}, config)
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{port: 70000}`, "$.port: cannot unmarshal number 70000 into Go value of type uint16"},
+		{`{servers: [{port: 'x'}]}`, "$.servers[0].port: cannot unmarshal string into Go value of type int"},
+		{`{level: 'trace'}`, `$.level: unknown level "trace"`},
+		{`{byName: []}`, "$.byName: expected object but found array"},
+		{`{} {}`, "1:4: expected end of input but found '{'"},
+	}
+	for _, test := range tests {
+		var config Config
+		assert.EqualError(t, json5.Unmarshal([]byte(test.input), &config), test.expected, test.input)
+	}
+}
+
+func TestUnmarshalJSON5(t *testing.T) {
+	var server Server
+	assert.NoError(t, server.UnmarshalJSON5([]byte(`{host: 'x', port: 1}`)))
+	assert.Equal(t, Server{Host: "x", Port: 1}, server)
+	assert.EqualError(t, server.UnmarshalJSON5([]byte(`{} {}`)), "1:4: expected end of input but found '{'")
+}
@@ -0,0 +1,835 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// json5Package is the import path of the package the generated code uses
+const json5Package = "github.com/shoobyban/json5"
+
+// pkgInfo is a parsed package: its type declarations and the methods declared on them
+type pkgInfo struct {
+	name    string
+	files   map[string]*ast.File     // by base name
+	decls   map[string]*ast.TypeSpec // type declarations by name
+	order   []string                 // type names in declaration order, files sorted by name
+	fileOf  map[string]string        // file declaring each type
+	methods map[string]map[string]bool
+	imports map[string]string // import paths by local name, for imports renamed in the source
+	paths   []string          // the other import paths, whose names are those of their packages
+}
+
+// loadPackage parses the Go files of dir, except tests and the output file itself
+func loadPackage(dir, output string) (*pkgInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	pkg := &pkgInfo{
+		files:   make(map[string]*ast.File),
+		decls:   make(map[string]*ast.TypeSpec),
+		fileOf:  make(map[string]string),
+		methods: make(map[string]map[string]bool),
+		imports: make(map[string]string),
+	}
+	fset := token.NewFileSet()
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") || name == filepath.Base(output) {
+			continue
+		}
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		if pkg.name == "" {
+			pkg.name = file.Name.Name
+		} else if pkg.name != file.Name.Name {
+			return nil, fmt.Errorf("%s: found packages %s and %s", dir, pkg.name, file.Name.Name)
+		}
+		pkg.files[name] = file
+	}
+	if len(pkg.files) == 0 {
+		return nil, fmt.Errorf("%s: no Go files", dir)
+	}
+
+	var names []string
+	for name := range pkg.files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, spec := range pkg.files[name].Imports {
+			path, _ := strconv.Unquote(spec.Path.Value)
+			if spec.Name == nil {
+				pkg.paths = append(pkg.paths, path)
+			} else if spec.Name.Name != "_" && spec.Name.Name != "." {
+				pkg.imports[spec.Name.Name] = path
+			}
+		}
+		for _, decl := range pkg.files[name].Decls {
+			switch decl := decl.(type) {
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					if ts, ok := spec.(*ast.TypeSpec); ok && ts.TypeParams == nil {
+						pkg.decls[ts.Name.Name] = ts
+						pkg.order = append(pkg.order, ts.Name.Name)
+						pkg.fileOf[ts.Name.Name] = name
+					}
+				}
+			case *ast.FuncDecl:
+				if decl.Recv == nil || len(decl.Recv.List) == 0 {
+					continue
+				}
+				recv := decl.Recv.List[0].Type
+				if star, ok := recv.(*ast.StarExpr); ok {
+					recv = star.X
+				}
+				if ident, ok := recv.(*ast.Ident); ok {
+					if pkg.methods[ident.Name] == nil {
+						pkg.methods[ident.Name] = make(map[string]bool)
+					}
+					pkg.methods[ident.Name][decl.Name.Name] = true
+				}
+			}
+		}
+	}
+	return pkg, nil
+}
+
+// generate returns the source of the codecs for the named types, or for the exported struct types
+// declared in files (all files if nil) when no type is named
+func generate(pkg *pkgInfo, files []string, typeNames []string) ([]byte, error) {
+	if len(typeNames) == 0 {
+		inFiles := make(map[string]bool)
+		for _, file := range files {
+			if pkg.files[file] == nil {
+				return nil, fmt.Errorf("%s: not a Go file of package %s", file, pkg.name)
+			}
+			inFiles[file] = true
+		}
+		for _, name := range pkg.order {
+			if _, ok := pkg.decls[name].Type.(*ast.StructType); ok && ast.IsExported(name) && (files == nil || inFiles[pkg.fileOf[name]]) {
+				typeNames = append(typeNames, name)
+			}
+		}
+		if len(typeNames) == 0 {
+			return nil, fmt.Errorf("no exported struct types found")
+		}
+	}
+
+	g := &generator{
+		pkg:       pkg,
+		generated: make(map[string]bool),
+		importer:  importer.ForCompiler(token.NewFileSet(), "source", nil),
+		loaded:    make(map[string]*types.Package),
+		used:      make(map[string]*types.Package),
+	}
+	for _, name := range typeNames {
+		ts, ok := pkg.decls[name]
+		if !ok {
+			return nil, fmt.Errorf("type %s not found in package %s", name, pkg.name)
+		}
+		if _, ok := ts.Type.(*ast.StructType); !ok {
+			return nil, fmt.Errorf("type %s is not a struct", name)
+		}
+		g.generated[name] = true
+	}
+
+	for _, name := range typeNames {
+		if err := g.generateType(name); err != nil {
+			return nil, err
+		}
+	}
+
+	// The imports are those the generated code turned out to use, the standard library first
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by json5gen; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n\n", pkg.name)
+	var std, other []string
+	other = append(other, strconv.Quote(json5Package))
+	for name, p := range g.used {
+		spec := strconv.Quote(p.Path())
+		if name != p.Name() {
+			spec = name + " " + spec
+		}
+		if strings.Contains(strings.Split(p.Path(), "/")[0], ".") {
+			other = append(other, spec)
+		} else {
+			std = append(std, spec)
+		}
+	}
+	sort.Strings(std)
+	sort.Strings(other)
+	groups := []string{strings.Join(other, "\n\t")}
+	if len(std) > 0 {
+		groups = append([]string{strings.Join(std, "\n\t")}, groups...)
+	}
+	fmt.Fprintf(&out, "import (\n\t%s\n)\n", strings.Join(groups, "\n\n\t"))
+	out.Write(g.buf.Bytes())
+
+	src, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("formatting generated code: %v\n%s", err, out.Bytes())
+	}
+	return src, nil
+}
+
+// generator accumulates the generated source
+type generator struct {
+	pkg       *pkgInfo
+	generated map[string]bool // struct types getting codecs, whose ReadJSON5 and WriteJSON5 can be called
+	buf       bytes.Buffer
+	vars      int // counter for unique variable names
+	importer  types.Importer
+	loaded    map[string]*types.Package // imported packages by local name
+	used      map[string]*types.Package // imported packages the generated code refers to, by local name
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// newVar returns a fresh variable name starting with prefix
+func (g *generator) newVar(prefix string) string {
+	g.vars++
+	return prefix + strconv.Itoa(g.vars)
+}
+
+// structField is a field of a generated struct, after promoting the fields of embedded structs
+type structField struct {
+	key       string   // key in the JSON5 object
+	selector  string   // path from the receiver, such as Base.ID
+	typ       ast.Expr // type of the field
+	omitEmpty bool
+	depth     int
+	tagged    bool
+}
+
+// generateType writes the four methods of a struct type
+func (g *generator) generateType(name string) error {
+	fields, err := g.structFields(name, "", 0, map[string]bool{})
+	if err != nil {
+		return err
+	}
+	fields = dominantFields(fields)
+	for _, f := range fields {
+		if err := g.check(f.typ); err != nil {
+			return fmt.Errorf("%s.%s: %v", name, f.selector, err)
+		}
+	}
+
+	g.printf("\n// MarshalJSON5 implements json5.Marshaler\n")
+	g.printf("func (v %s) MarshalJSON5() ([]byte, error) {\n", name)
+	g.printf("w := json5.NewWriter()\nv.WriteJSON5(w)\nreturn w.Bytes(), w.Err()\n}\n")
+
+	g.printf("\n// WriteJSON5 writes v as a JSON5 object to w\n")
+	g.printf("func (v %s) WriteJSON5(w *json5.Writer) {\n", name)
+	g.printf("w.BeginObject()\n")
+	for _, f := range fields {
+		value := "v." + f.selector
+		if f.omitEmpty {
+			if cond := g.nonEmpty(f.typ, value); cond != "" {
+				g.printf("if %s {\n", cond)
+				g.printf("w.Key(%q)\n", f.key)
+				g.writeValue(f.typ, value, true)
+				g.printf("}\n")
+				continue
+			}
+		}
+		g.printf("w.Key(%q)\n", f.key)
+		g.writeValue(f.typ, value, false)
+	}
+	g.printf("w.EndObject()\n}\n")
+
+	g.printf("\n// UnmarshalJSON5 implements json5.Unmarshaler\n")
+	g.printf("func (v *%s) UnmarshalJSON5(data []byte) error {\n", name)
+	g.printf("r := json5.NewReader(data)\nif err := v.ReadJSON5(r); err != nil {\nreturn err\n}\nreturn r.End()\n}\n")
+
+	g.printf("\n// ReadJSON5 reads v from a JSON5 object in r; keys match exactly or else ignoring case, unknown keys are skipped\n")
+	g.printf("func (v *%s) ReadJSON5(r *json5.Reader) error {\n", name)
+	g.printf("return r.ReadObject(func(key string) error {\n")
+	if len(fields) > 0 {
+		keys := make([]string, len(fields))
+		for i, f := range fields {
+			keys[i] = strconv.Quote(f.key)
+		}
+		g.printf("switch r.MatchKey(key, %s) {\n", strings.Join(keys, ", "))
+		for _, f := range fields {
+			g.printf("case %q:\n", f.key)
+			g.readValue(f.typ, "v."+f.selector, false)
+			g.printf("return nil\n")
+		}
+		g.printf("}\n")
+	}
+	g.printf("return r.Skip()\n})\n}\n")
+	return nil
+}
+
+// structFields lists the fields of the struct type name, promoting the fields of embedded structs
+func (g *generator) structFields(name, prefix string, depth int, visiting map[string]bool) ([]structField, error) {
+	if visiting[name] {
+		return nil, nil
+	}
+	visiting[name] = true
+	defer delete(visiting, name)
+
+	st := g.pkg.decls[name].Type.(*ast.StructType)
+	var fields []structField
+	for _, field := range st.Fields.List {
+		key, opts, tagged := parseTag(field)
+		if key == "-" && len(opts) == 0 {
+			continue
+		}
+		for _, opt := range opts {
+			if opt == "string" {
+				return nil, fmt.Errorf("%s: the string tag option is not supported", name)
+			}
+		}
+
+		if len(field.Names) == 0 {
+			// Embedded field
+			ident, ok := field.Type.(*ast.Ident)
+			if !ok {
+				return nil, fmt.Errorf("%s: embedded field %s is not supported, only embedded structs of the same package",
+					name, types.ExprString(field.Type))
+			}
+			if decl, ok := g.pkg.decls[ident.Name]; ok && key == "" {
+				if _, ok := decl.Type.(*ast.StructType); ok {
+					embedded, err := g.structFields(ident.Name, prefix+ident.Name+".", depth+1, visiting)
+					if err != nil {
+						return nil, err
+					}
+					fields = append(fields, embedded...)
+					continue
+				}
+			}
+			if !ast.IsExported(ident.Name) {
+				continue
+			}
+			fields = append(fields, g.newField(key, ident.Name, prefix, field, opts, tagged, depth))
+			continue
+		}
+
+		for _, fieldName := range field.Names {
+			if !ast.IsExported(fieldName.Name) {
+				continue
+			}
+			fields = append(fields, g.newField(key, fieldName.Name, prefix, field, opts, tagged, depth))
+		}
+	}
+	return fields, nil
+}
+
+// newField builds a structField for the Go field goName
+func (g *generator) newField(key, goName, prefix string, field *ast.Field, opts []string, tagged bool, depth int) structField {
+	f := structField{key: key, selector: prefix + goName, typ: field.Type, depth: depth, tagged: tagged && key != ""}
+	if f.key == "" {
+		f.key = goName
+	}
+	for _, opt := range opts {
+		if opt == "omitempty" {
+			f.omitEmpty = true
+		}
+	}
+	return f
+}
+
+// parseTag returns the name and options of the json5 tag of a field, falling back to its json tag
+func parseTag(field *ast.Field) (string, []string, bool) {
+	if field.Tag == nil {
+		return "", nil, false
+	}
+	raw, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return "", nil, false
+	}
+	tag := reflect.StructTag(raw)
+	value, ok := tag.Lookup("json5")
+	if !ok {
+		value, ok = tag.Lookup("json")
+	}
+	parts := strings.Split(value, ",")
+	return parts[0], parts[1:], ok
+}
+
+// dominantFields keeps, for each key, the field Unmarshal would use: the shallowest, or the tagged one
+// among equally shallow fields; ambiguous keys are dropped
+func dominantFields(fields []structField) []structField {
+	byKey := make(map[string][]structField)
+	for _, f := range fields {
+		byKey[f.key] = append(byKey[f.key], f)
+	}
+
+	keep := make(map[string]structField)
+	for key, candidates := range byKey {
+		depth := candidates[0].depth
+		for _, f := range candidates {
+			if f.depth < depth {
+				depth = f.depth
+			}
+		}
+		var shallowest, tagged []structField
+		for _, f := range candidates {
+			if f.depth == depth {
+				shallowest = append(shallowest, f)
+				if f.tagged {
+					tagged = append(tagged, f)
+				}
+			}
+		}
+		switch {
+		case len(shallowest) == 1:
+			keep[key] = shallowest[0]
+		case len(tagged) == 1:
+			keep[key] = tagged[0]
+		}
+	}
+
+	var result []structField
+	for _, f := range fields {
+		if k, ok := keep[f.key]; ok && k.selector == f.selector {
+			result = append(result, f)
+		}
+	}
+	return result
+}
+
+// basicKind describes how a predeclared type is read and written
+type basicKind struct {
+	read    string // Reader method call
+	write   string // Writer method, called with the value converted to conv
+	conv    string
+	bitSize string
+}
+
+var basicKinds = map[string]basicKind{
+	"string":  {read: "r.ReadString()", write: "String", conv: "string"},
+	"bool":    {read: "r.ReadBool()", write: "Bool", conv: "bool"},
+	"int":     {read: "r.ReadInt(0)", write: "Int", conv: "int64"},
+	"int8":    {read: "r.ReadInt(8)", write: "Int", conv: "int64"},
+	"int16":   {read: "r.ReadInt(16)", write: "Int", conv: "int64"},
+	"int32":   {read: "r.ReadInt(32)", write: "Int", conv: "int64"},
+	"rune":    {read: "r.ReadInt(32)", write: "Int", conv: "int64"},
+	"int64":   {read: "r.ReadInt(64)", write: "Int", conv: "int64"},
+	"uint":    {read: "r.ReadUint(0)", write: "Uint", conv: "uint64"},
+	"uint8":   {read: "r.ReadUint(8)", write: "Uint", conv: "uint64"},
+	"byte":    {read: "r.ReadUint(8)", write: "Uint", conv: "uint64"},
+	"uint16":  {read: "r.ReadUint(16)", write: "Uint", conv: "uint64"},
+	"uint32":  {read: "r.ReadUint(32)", write: "Uint", conv: "uint64"},
+	"uint64":  {read: "r.ReadUint(64)", write: "Uint", conv: "uint64"},
+	"float32": {read: "r.ReadFloat(32)", write: "Float", conv: "float64", bitSize: "32"},
+	"float64": {read: "r.ReadFloat(64)", write: "Float", conv: "float64", bitSize: "64"},
+}
+
+// customMethods are the methods that make a type encode itself, so it cannot be handled as its underlying type
+var customMethods = []string{"MarshalJSON5", "UnmarshalJSON5", "MarshalText", "UnmarshalText", "MarshalJSON", "UnmarshalJSON"}
+
+// resolve returns the underlying type of a type declared in this package, unless it is a struct or has
+// custom methods; other types are returned unchanged
+func (g *generator) resolve(t ast.Expr) ast.Expr {
+	for {
+		ident, ok := t.(*ast.Ident)
+		if !ok {
+			return t
+		}
+		decl, ok := g.pkg.decls[ident.Name]
+		if !ok {
+			return t
+		}
+		if _, ok := decl.Type.(*ast.StructType); ok {
+			return t
+		}
+		for _, method := range customMethods {
+			if g.pkg.methods[ident.Name][method] {
+				return t
+			}
+		}
+		t = decl.Type
+	}
+}
+
+// basic returns how t is read and written if it is a predeclared type, or declared as one in this package
+// or, without custom methods, in another one, such as time.Duration
+func (g *generator) basic(t ast.Expr) (basicKind, bool) {
+	switch u := g.resolve(t).(type) {
+	case *ast.Ident:
+		kind, ok := basicKinds[u.Name]
+		return kind, ok
+	case *ast.SelectorExpr:
+		obj, _ := g.foreign(u)
+		if obj == nil {
+			return basicKind{}, false
+		}
+		for _, method := range customMethods {
+			if g.hasMethod(u, method) {
+				return basicKind{}, false
+			}
+		}
+		if b, ok := obj.Type().Underlying().(*types.Basic); ok {
+			kind, ok := basicKinds[b.Name()]
+			return kind, ok
+		}
+	}
+	return basicKind{}, false
+}
+
+// custom reports whether t encodes itself with MarshalJSON5 and UnmarshalJSON5 or MarshalText and
+// UnmarshalText methods, which json5 calls without reflection
+func (g *generator) custom(t ast.Expr) bool {
+	t = g.resolve(t)
+	return (g.hasMethod(t, "MarshalJSON5") || g.hasMethod(t, "MarshalText")) &&
+		(g.hasMethod(t, "UnmarshalJSON5") || g.hasMethod(t, "UnmarshalText"))
+}
+
+// hasMethod reports whether the named type t, of this package or another one, or its pointer has the method
+func (g *generator) hasMethod(t ast.Expr, method string) bool {
+	switch t := t.(type) {
+	case *ast.Ident:
+		return g.pkg.methods[t.Name][method]
+	case *ast.SelectorExpr:
+		obj, _ := g.foreign(t)
+		if obj == nil {
+			return false
+		}
+		return types.NewMethodSet(types.NewPointer(obj.Type())).Lookup(obj.Pkg(), method) != nil
+	}
+	return false
+}
+
+// foreign returns the type of another package named by sel, such as time.Duration
+func (g *generator) foreign(sel *ast.SelectorExpr) (*types.TypeName, error) {
+	x, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return nil, fmt.Errorf("type %s is not supported", types.ExprString(sel))
+	}
+	p, err := g.load(x.Name)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := p.Scope().Lookup(sel.Sel.Name).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("type %s not found", types.ExprString(sel))
+	}
+	return obj, nil
+}
+
+// load returns the package imported as name, type-checking it from source on first use
+func (g *generator) load(name string) (*types.Package, error) {
+	if p, ok := g.loaded[name]; ok {
+		if p == nil {
+			return nil, fmt.Errorf("package %s not found", name)
+		}
+		return p, nil
+	}
+	g.loaded[name] = nil
+
+	var candidates []string
+	if path, ok := g.pkg.imports[name]; ok {
+		candidates = []string{path}
+	} else {
+		// Packages are mostly named after the last element of their import path, so those are tried first
+		for _, path := range g.pkg.paths {
+			if filepath.Base(path) == name {
+				candidates = append([]string{path}, candidates...)
+			} else {
+				candidates = append(candidates, path)
+			}
+		}
+	}
+	for _, path := range candidates {
+		p, err := g.importer.Import(path)
+		if err != nil {
+			return nil, fmt.Errorf("loading package %s: %v", path, err)
+		}
+		if p.Name() == name || g.pkg.imports[name] == path {
+			g.loaded[name] = p
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("package %s not found", name)
+}
+
+// check reports an error if the code reading or writing t would need reflection at run time, so the
+// codecs also work on TinyGo
+func (g *generator) check(t ast.Expr) error {
+	if _, ok := g.basic(t); ok {
+		return nil
+	}
+	if _, ok := g.generatedStruct(t); ok || g.custom(t) {
+		return nil
+	}
+	switch u := g.resolve(t).(type) {
+	case *ast.StarExpr:
+		return g.check(u.X)
+	case *ast.ArrayType:
+		if u.Len == nil {
+			return g.check(u.Elt)
+		}
+	case *ast.MapType:
+		if ident, ok := u.Key.(*ast.Ident); ok && ident.Name == "string" {
+			return g.check(u.Value)
+		}
+	case *ast.SelectorExpr:
+		if _, err := g.foreign(u); err != nil {
+			return err
+		}
+	case *ast.Ident:
+		if decl, ok := g.pkg.decls[u.Name]; ok {
+			if _, ok := decl.Type.(*ast.StructType); ok {
+				return fmt.Errorf("struct type %s has no generated codecs, add it to -type", u.Name)
+			}
+		}
+	}
+	if isInterface(g.resolve(t)) {
+		return nil
+	}
+	return fmt.Errorf("type %s would need reflection: give it MarshalJSON5 and UnmarshalJSON5 or MarshalText and UnmarshalText methods",
+		types.ExprString(t))
+}
+
+// typeString returns t as Go source, noting the imported packages it refers to
+func (g *generator) typeString(t ast.Expr) string {
+	ast.Inspect(t, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if x, ok := sel.X.(*ast.Ident); ok {
+			if p, err := g.load(x.Name); err == nil {
+				g.used[x.Name] = p
+			}
+		}
+		return false
+	})
+	return types.ExprString(t)
+}
+
+// isInterface reports whether t is interface{} or any
+func isInterface(t ast.Expr) bool {
+	switch t := t.(type) {
+	case *ast.Ident:
+		return t.Name == "any"
+	case *ast.InterfaceType:
+		return len(t.Methods.List) == 0
+	}
+	return false
+}
+
+// generatedStruct returns the name of t if it is a struct type getting codecs
+func (g *generator) generatedStruct(t ast.Expr) (string, bool) {
+	ident, ok := t.(*ast.Ident)
+	if !ok || !g.generated[ident.Name] {
+		return "", false
+	}
+	return ident.Name, true
+}
+
+// nonEmpty returns the condition under which an omitempty field is written, or "" if it always is
+func (g *generator) nonEmpty(t ast.Expr, value string) string {
+	if kind, ok := g.basic(t); ok {
+		switch kind.conv {
+		case "string":
+			return "len(" + value + ") != 0"
+		case "bool":
+			return value
+		default:
+			return value + " != 0"
+		}
+	}
+	switch t := g.resolve(t).(type) {
+	case *ast.StarExpr:
+		return value + " != nil"
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return "len(" + value + ") != 0"
+		}
+	case *ast.MapType:
+		return "len(" + value + ") != 0"
+	}
+	if isInterface(g.resolve(t)) {
+		return value + " != nil"
+	}
+	return ""
+}
+
+// writeValue writes the code writing value, of type t, to w; notNil tells that value is known not to be nil,
+// as an omitempty field once checked
+func (g *generator) writeValue(t ast.Expr, value string, notNil bool) {
+	if kind, ok := g.basic(t); ok {
+		if kind.bitSize != "" {
+			g.printf("w.%s(%s(%s), %s)\n", kind.write, kind.conv, value, kind.bitSize)
+		} else {
+			g.printf("w.%s(%s(%s))\n", kind.write, kind.conv, value)
+		}
+		return
+	}
+	if _, ok := g.generatedStruct(t); ok {
+		g.printf("%s.WriteJSON5(w)\n", value)
+		return
+	}
+
+	switch u := g.resolve(t).(type) {
+	case *ast.StarExpr:
+		g.writeNullable(value, notNil, func() {
+			if _, ok := g.generatedStruct(u.X); ok {
+				g.printf("%s.WriteJSON5(w)\n", value)
+			} else {
+				g.writeValue(u.X, "(*"+value+")", false)
+			}
+		})
+		return
+	case *ast.ArrayType:
+		if u.Len == nil {
+			item := g.newVar("item")
+			g.writeNullable(value, notNil, func() {
+				g.printf("w.BeginArray()\nfor _, %s := range %s {\n", item, value)
+				g.writeValue(u.Elt, item, false)
+				g.printf("}\nw.EndArray()\n")
+			})
+			return
+		}
+	case *ast.MapType:
+		if ident, ok := u.Key.(*ast.Ident); ok && ident.Name == "string" {
+			keys, key := g.newVar("keys"), g.newVar("key")
+			g.writeNullable(value, notNil, func() {
+				g.printf("%s := make([]string, 0, len(%s))\n", keys, value)
+				g.printf("for %s := range %s {\n%s = append(%s, %s)\n}\n", key, value, keys, keys, key)
+				g.printf("w.BeginObject()\nfor _, %s := range w.SortKeys(%s) {\n", key, keys)
+				g.printf("w.Key(%s)\n", key)
+				g.writeValue(u.Value, value+"["+key+"]", false)
+				g.printf("}\nw.EndObject()\n")
+			})
+			return
+		}
+	}
+
+	// Interfaces and types with their own methods go through Marshal, which calls them
+	g.printf("w.Value(%s)\n", value)
+}
+
+// writeNullable writes the code of write, preceded by writing null instead if value is nil and may be
+func (g *generator) writeNullable(value string, notNil bool, write func()) {
+	if notNil {
+		write()
+		return
+	}
+	g.printf("if %s == nil {\nw.Null()\n} else {\n", value)
+	write()
+	g.printf("}\n")
+}
+
+// readValue writes the code reading a value of type t, or null, from r into dst, returning from the enclosing
+// function on error; fresh tells that dst holds the zero value of t. null leaves basic types and structs
+// unchanged and sets pointers, slices and maps to nil.
+func (g *generator) readValue(t ast.Expr, dst string, fresh bool) {
+	_, basic := g.basic(t)
+	_, generated := g.generatedStruct(t)
+	switch {
+	case basic || generated:
+		g.printf("if !r.ReadNull() {\n")
+		g.readNonNull(t, dst, fresh)
+		g.printf("}\n")
+	case g.nullable(t) && fresh:
+		g.printf("if !r.ReadNull() {\n")
+		g.readNonNull(t, dst, fresh)
+		g.printf("}\n")
+	case g.nullable(t):
+		g.printf("if r.ReadNull() {\n%s = nil\n} else {\n", dst)
+		g.readNonNull(t, dst, fresh)
+		g.printf("}\n")
+	default:
+		// Interfaces and types with their own methods take null themselves
+		g.readNonNull(t, dst, fresh)
+	}
+}
+
+// nullable reports whether t is a pointer, slice or map type that readNonNull reads itself
+func (g *generator) nullable(t ast.Expr) bool {
+	switch u := g.resolve(t).(type) {
+	case *ast.StarExpr:
+		return true
+	case *ast.ArrayType:
+		return u.Len == nil
+	case *ast.MapType:
+		ident, ok := u.Key.(*ast.Ident)
+		return ok && ident.Name == "string"
+	}
+	return false
+}
+
+// readNonNull writes the code of readValue for a value known not to be null
+func (g *generator) readNonNull(t ast.Expr, dst string, fresh bool) {
+	if kind, ok := g.basic(t); ok {
+		n := g.newVar("n")
+		g.printf("%s, err := %s\nif err != nil {\nreturn err\n}\n", n, kind.read)
+		g.printf("%s = %s(%s)\n", dst, g.typeString(t), n)
+		return
+	}
+	if _, ok := g.generatedStruct(t); ok {
+		g.printf("if err := %s.ReadJSON5(r); err != nil {\nreturn err\n}\n", dst)
+		return
+	}
+
+	switch u := g.resolve(t).(type) {
+	case *ast.StarExpr:
+		// An existing value is read into, as Unmarshal does
+		if fresh {
+			g.printf("%s = new(%s)\n", dst, g.typeString(u.X))
+		} else {
+			g.printf("if %s == nil {\n%s = new(%s)\n}\n", dst, dst, g.typeString(u.X))
+		}
+		if _, ok := g.generatedStruct(u.X); ok {
+			g.printf("if err := %s.ReadJSON5(r); err != nil {\nreturn err\n}\n", dst)
+		} else {
+			g.readNonNull(u.X, "(*"+dst+")", fresh)
+		}
+		return
+	case *ast.ArrayType:
+		if u.Len == nil {
+			slice, item := g.newVar("slice"), g.newVar("item")
+			g.printf("%s := %s{}\n", slice, g.typeString(t))
+			g.printf("if err := r.ReadArray(func(int) error {\n")
+			g.printf("var %s %s\n", item, g.typeString(u.Elt))
+			g.readValue(u.Elt, item, true)
+			g.printf("%s = append(%s, %s)\nreturn nil\n", slice, slice, item)
+			g.printf("}); err != nil {\nreturn err\n}\n")
+			g.printf("%s = %s\n", dst, slice)
+			return
+		}
+	case *ast.MapType:
+		if ident, ok := u.Key.(*ast.Ident); ok && ident.Name == "string" {
+			m, item := g.newVar("m"), g.newVar("item")
+			g.printf("%s := %s{}\n", m, g.typeString(t))
+			g.printf("if err := r.ReadObject(func(key string) error {\n")
+			g.printf("var %s %s\n", item, g.typeString(u.Value))
+			g.readValue(u.Value, item, true)
+			g.printf("%s[key] = %s\nreturn nil\n", m, item)
+			g.printf("}); err != nil {\nreturn err\n}\n")
+			g.printf("%s = %s\n", dst, m)
+			return
+		}
+	}
+
+	if isInterface(g.resolve(t)) {
+		n := g.newVar("n")
+		g.printf("%s, err := r.ReadValue()\nif err != nil {\nreturn err\n}\n%s = %s\n", n, dst, n)
+		return
+	}
+
+	// Types with their own methods go through Decode, which calls them
+	g.printf("if err := r.Decode(&%s); err != nil {\nreturn err\n}\n", dst)
+}
@@ -0,0 +1,106 @@
+// Command json5gen generates reflection-free JSON5 codecs for Go struct types.
+//
+// For every struct type it writes MarshalJSON5 and UnmarshalJSON5 methods, implementing json5.Marshaler and
+// json5.Unmarshaler, that walk the token stream with json5.Reader and json5.Writer instead of going through
+// map[string]interface{} or reflection, so typed decoding also works on TinyGo. Use it with go generate:
+//
+//	//go:generate go run github.com/shoobyban/json5/cmd/json5gen -type Config,Server
+//
+// Without -type, codecs are generated for every exported struct type declared in the input files. The
+// input defaults to $GOFILE when run by go generate, and to the package in the current directory otherwise;
+// the other files of the package are read to resolve the types the structs refer to.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	typeNames := flag.String("type", "", "comma-separated list of the struct types to generate codecs for")
+	output := flag.String("output", "", "output file name; default <file>_json5.go or <package>_json5.go")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: json5gen [-type T1,T2] [-output file] [file.go ... | directory]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) == 0 {
+		if gofile := os.Getenv("GOFILE"); gofile != "" {
+			args = []string{gofile}
+		} else {
+			args = []string{"."}
+		}
+	}
+
+	var types []string
+	if *typeNames != "" {
+		types = strings.Split(*typeNames, ",")
+	}
+
+	if err := run(args, types, *output); err != nil {
+		fmt.Fprintln(os.Stderr, "json5gen:", err)
+		os.Exit(1)
+	}
+}
+
+// run generates the codecs for the given files or directory and writes them to output
+func run(args []string, types []string, output string) error {
+	dir, files, err := inputFiles(args)
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		output = defaultOutput(args)
+	}
+	output = filepath.Join(dir, filepath.Base(output))
+
+	pkg, err := loadPackage(dir, output)
+	if err != nil {
+		return err
+	}
+	src, err := generate(pkg, files, types)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(output, src, 0o644)
+}
+
+// inputFiles returns the directory of the package and the files whose types are generated,
+// or nil for all the files of the package
+func inputFiles(args []string) (string, []string, error) {
+	if len(args) == 1 {
+		if info, err := os.Stat(args[0]); err == nil && info.IsDir() {
+			return args[0], nil, nil
+		}
+	}
+	dir := filepath.Dir(args[0])
+	var files []string
+	for _, arg := range args {
+		if filepath.Dir(arg) != dir {
+			return "", nil, fmt.Errorf("files must be in the same directory: %s", strings.Join(args, " "))
+		}
+		files = append(files, filepath.Base(arg))
+	}
+	return dir, files, nil
+}
+
+// defaultOutput names the output file after the single input file, or after the package directory
+func defaultOutput(args []string) string {
+	if len(args) == 1 && strings.HasSuffix(args[0], ".go") {
+		return strings.TrimSuffix(filepath.Base(args[0]), ".go") + "_json5.go"
+	}
+	dir := args[0]
+	if strings.HasSuffix(dir, ".go") {
+		dir = filepath.Dir(dir)
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		abs = dir
+	}
+	return filepath.Base(abs) + "_json5.go"
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateExample(t *testing.T) {
+	expected, err := os.ReadFile("example/example_json5.go")
+	assert.NoError(t, err)
+
+	dir := t.TempDir()
+	src, err := os.ReadFile("example/example.go")
+	assert.NoError(t, err)
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "example.go"), src, 0o644))
+	assert.NoError(t, run([]string{filepath.Join(dir, "example.go")}, nil, ""))
+
+	result, err := os.ReadFile(filepath.Join(dir, "example_json5.go"))
+	assert.NoError(t, err)
+	assert.Equal(t, string(expected), string(result), "example_json5.go is out of date, run go generate ./...")
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tests := []struct {
+		src      string
+		types    []string
+		expected string
+	}{
+		{"package p\n\ntype T struct{ A int }\n", []string{"U"}, "type U not found in package p"},
+		{"package p\n\ntype T int\n", []string{"T"}, "type T is not a struct"},
+		{"package p\n\ntype t struct{ A int }\n", nil, "no exported struct types found"},
+		{"package p\n\ntype T struct {\n\tA int `json5:\",string\"`\n}\n", nil, "T: the string tag option is not supported"},
+		{"package p\n\ntype T struct{ M map[int]string }\n", nil,
+			"T.M: type map[int]string would need reflection: give it MarshalJSON5 and UnmarshalJSON5 or MarshalText and UnmarshalText methods"},
+		{"package p\n\nimport \"net/url\"\n\ntype T struct{ U url.URL }\n", nil,
+			"T.U: type url.URL would need reflection: give it MarshalJSON5 and UnmarshalJSON5 or MarshalText and UnmarshalText methods"},
+		{"package p\n\ntype T struct{ S S }\n\ntype S struct{ A int }\n", []string{"T"}, "T.S: struct type S has no generated codecs, add it to -type"},
+		{"package p\n\ntype T struct{ A x.A }\n", nil, "T.A: package x not found"},
+	}
+	for _, test := range tests {
+		dir := t.TempDir()
+		file := filepath.Join(dir, "p.go")
+		assert.NoError(t, os.WriteFile(file, []byte(test.src), 0o644))
+		assert.EqualError(t, run([]string{file}, test.types, ""), test.expected)
+	}
+}
+
+func TestGenerateForeignTypes(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "p.go")
+	src := "package p\n\nimport (\n\t\"time\"\n\n\tt \"text/template\"\n)\n\n" +
+		"type T struct {\n\tD time.Duration\n\tAt time.Time\n\tDs []time.Duration\n\tTree *t.Template\n}\n"
+	assert.NoError(t, os.WriteFile(file, []byte(src), 0o644))
+	err := run([]string{file}, nil, "")
+	assert.EqualError(t, err, "T.Tree: type t.Template would need reflection: "+
+		"give it MarshalJSON5 and UnmarshalJSON5 or MarshalText and UnmarshalText methods")
+
+	src = strings.Replace(src, "\tTree *t.Template\n", "", 1)
+	src = strings.Replace(src, "\n\tt \"text/template\"\n", "", 1)
+	assert.NoError(t, os.WriteFile(file, []byte(src), 0o644))
+	assert.NoError(t, run([]string{file}, nil, ""))
+	result, err := os.ReadFile(filepath.Join(dir, "p_json5.go"))
+	assert.NoError(t, err)
+	// Durations are integers, times encode themselves as text
+	assert.Contains(t, string(result), "import (\n\t\"time\"\n\n\t\"github.com/shoobyban/json5\"\n)")
+	assert.Contains(t, string(result), "w.Int(int64(v.D))")
+	assert.Contains(t, string(result), "w.Value(v.At)")
+	assert.Contains(t, string(result), "= time.Duration(")
+}
+
+// TestExampleTinyGo runs the tests of the example as TinyGo builds them, without reflection
+func TestExampleTinyGo(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs go test")
+	}
+	out, err := exec.Command("go", "test", "-tags", "tinygo", "./example").CombinedOutput()
+	assert.NoError(t, err, string(out))
+}
+
+func TestDefaultOutput(t *testing.T) {
+	assert.Equal(t, "config_json5.go", defaultOutput([]string{"dir/config.go"}))
+	assert.Equal(t, "dir_json5.go", defaultOutput([]string{"a/dir"}))
+	assert.Equal(t, "a_json5.go", defaultOutput([]string{"a/x.go", "a/y.go"}))
+}
@@ -0,0 +1,196 @@
+package json5
+
+import (
+	"encoding/json"
+	"io/fs"
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The json5-tests corpus (https://github.com/json5/json5-tests) sorts its cases by extension:
+// .json is valid JSON and JSON5, .json5 is valid JSON5 only, .js is valid ES5 but not JSON5,
+// and .txt is invalid everywhere.
+const conformanceDir = "testdata/json5-tests"
+
+// conformanceExtensions lists the invalid cases accepted by default because of a non-standard extension,
+// they are only rejected in strict mode
+var conformanceExtensions = map[string]string{
+	"strings/unescaped-multi-line-string.txt": "raw line breaks in quoted strings",
+}
+
+// conformanceValues holds the expected result of each .json5 case; .json cases are checked against encoding/json
+var conformanceValues = map[string]interface{}{
+	"arrays/trailing-comma-array.json5":                       []interface{}{nil},
+	"comments/block-comment-following-array-element.json5":    []interface{}{false},
+	"comments/block-comment-following-top-level-value.json5":  nil,
+	"comments/block-comment-preceding-top-level-value.json5":  nil,
+	"comments/block-comment-with-asterisks.json5":             true,
+	"comments/inline-comment-following-array-element.json5":   []interface{}{false},
+	"comments/inline-comment-following-top-level-value.json5": nil,
+	"comments/inline-comment-preceding-top-level-value.json5": nil,
+	"misc/valid-whitespace.json5":                             map[string]interface{}{"a": true},
+	"misc/readme-example.json5": map[string]interface{}{
+		"foo": "bar", "while": true, "this": "is a multi-line string", "here": "is another",
+		"hex": 0xDEADbeef, "half": 0.5, "delta": 10, "to": math.Inf(1), "finally": "a trailing comma",
+		"oh": []interface{}{"we shouldn't forget", "arrays can have", "trailing commas too"},
+	},
+	"new-lines/comment-cr.json5":                                       map[string]interface{}{},
+	"new-lines/comment-crlf.json5":                                     map[string]interface{}{},
+	"new-lines/comment-lf.json5":                                       map[string]interface{}{},
+	"new-lines/escaped-cr.json5":                                       map[string]interface{}{"a": "line 1 line 2"},
+	"new-lines/escaped-crlf.json5":                                     map[string]interface{}{"a": "line 1 line 2"},
+	"new-lines/escaped-lf.json5":                                       map[string]interface{}{"a": "line 1 line 2"},
+	"numbers/float-leading-decimal-point.json5":                        0.5,
+	"numbers/float-trailing-decimal-point-with-integer-exponent.json5": 5e4,
+	"numbers/float-trailing-decimal-point.json5":                       5.0,
+	"numbers/hexadecimal-lowercase-letter.json5":                       200,
+	"numbers/hexadecimal-uppercase-x.json5":                            200,
+	"numbers/hexadecimal-with-integer-exponent.json5":                  0xc8e4,
+	"numbers/hexadecimal.json5":                                        200,
+	"numbers/infinity.json5":                                           math.Inf(1),
+	"numbers/nan.json5":                                                math.NaN(),
+	"numbers/negative-float-leading-decimal-point.json5":               -0.5,
+	"numbers/negative-float-trailing-decimal-point.json5":              -5.0,
+	"numbers/negative-hexadecimal.json5":                               -200,
+	"numbers/negative-infinity.json5":                                  math.Inf(-1),
+	"numbers/negative-zero-float-leading-decimal-point.json5":          math.Copysign(0, -1),
+	"numbers/negative-zero-float-trailing-decimal-point.json5":         math.Copysign(0, -1),
+	"numbers/negative-zero-hexadecimal.json5":                          0,
+	"numbers/positive-float-leading-decimal-point.json5":               0.5,
+	"numbers/positive-float-leading-zero.json5":                        0.5,
+	"numbers/positive-float-trailing-decimal-point.json5":              5.0,
+	"numbers/positive-float.json5":                                     1.2,
+	"numbers/positive-hexadecimal.json5":                               200,
+	"numbers/positive-infinity.json5":                                  math.Inf(1),
+	"numbers/positive-integer.json5":                                   15,
+	"numbers/positive-zero-float-leading-decimal-point.json5":          0.0,
+	"numbers/positive-zero-float-trailing-decimal-point.json5":         0.0,
+	"numbers/positive-zero-float.json5":                                0.0,
+	"numbers/positive-zero-hexadecimal.json5":                          0,
+	"numbers/positive-zero-integer.json5":                              0,
+	"numbers/zero-float-leading-decimal-point.json5":                   0.0,
+	"numbers/zero-float-trailing-decimal-point.json5":                  0.0,
+	"numbers/zero-hexadecimal.json5":                                   0,
+	"objects/reserved-unquoted-key.json5":                              map[string]interface{}{"while": true},
+	"objects/single-quoted-key.json5":                                  map[string]interface{}{"hello": "world"},
+	"objects/trailing-comma-object.json5":                              map[string]interface{}{"foo": "bar"},
+	"objects/unquoted-keys.json5": map[string]interface{}{
+		"hello": "world", "_": "underscore", "$": "dollar sign", "one1": "numerals",
+		"_$_": "multiple symbols", "$_$hello123world_$_": "mixed",
+	},
+	"strings/escaped-single-quoted-string.json5": "I can't wait",
+	"strings/multi-line-string.json5":            "hello world",
+	"strings/single-quoted-string.json5":         "hello world",
+	"todo/unicode-escaped-unquoted-key.json5":    map[string]interface{}{"sigΣma": "the sum of all things"},
+	"todo/unicode-unquoted-key.json5":            map[string]interface{}{"ümlåût": "that's not really an ümlaüt, but this is"},
+}
+
+func TestConformance(t *testing.T) {
+	err := filepath.WalkDir(conformanceDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		switch filepath.Ext(path) {
+		case ".json", ".json5", ".js", ".txt":
+		default:
+			return nil
+		}
+		name, _ := filepath.Rel(conformanceDir, path)
+		name = filepath.ToSlash(name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		t.Run(name, func(t *testing.T) {
+			// Strict mode accepts exactly the valid cases
+			_, strictErr := UnMarshalWithOptions(string(data), ParseOptions{Strict: true})
+			switch filepath.Ext(name) {
+			case ".json", ".json5":
+				assert.NoError(t, strictErr, "strict mode")
+			default:
+				assert.Error(t, strictErr, "strict mode")
+			}
+
+			// JSON mode accepts only the plain JSON cases
+			_, jsonErr := UnMarshalWithOptions(string(data), ParseOptions{JSON: true})
+			if filepath.Ext(name) == ".json" {
+				assert.NoError(t, jsonErr, "JSON mode")
+			} else {
+				assert.Error(t, jsonErr, "JSON mode")
+			}
+
+			result, err := UnMarshal(string(data))
+			switch filepath.Ext(name) {
+			case ".json":
+				if !assert.NoError(t, err) {
+					return
+				}
+				var expected interface{}
+				assert.NoError(t, json.Unmarshal(data, &expected))
+				assert.Equal(t, expected, normalizeNumbers(result))
+			case ".json5":
+				if !assert.NoError(t, err) {
+					return
+				}
+				if jsonData, err := os.ReadFile(path[:len(path)-1]); err == nil {
+					// The same document written as plain JSON
+					var expected interface{}
+					assert.NoError(t, json.Unmarshal(jsonData, &expected))
+					assert.Equal(t, expected, normalizeNumbers(result))
+					return
+				}
+				expected, ok := conformanceValues[name]
+				if !assert.True(t, ok, "no expected value for %s", name) {
+					return
+				}
+				if f, ok := expected.(float64); ok && math.IsNaN(f) {
+					assert.True(t, math.IsNaN(result.(float64)))
+					return
+				}
+				if f, ok := expected.(float64); ok && f == 0 {
+					// assert.Equal does not tell 0 from -0
+					assert.Equal(t, math.Signbit(f), math.Signbit(result.(float64)))
+				}
+				assert.Equal(t, expected, result)
+			case ".js", ".txt":
+				if reason, ok := conformanceExtensions[name]; ok {
+					assert.NoError(t, err, "accepted as an extension: %s", reason)
+					return
+				}
+				assert.Error(t, err)
+			}
+		})
+		return nil
+	})
+	assert.NoError(t, err)
+}
+
+// normalizeNumbers converts every integer in v to float64, the way encoding/json decodes numbers
+func normalizeNumbers(v interface{}) interface{} {
+	switch v := v.(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	case *big.Int:
+		f, _ := new(big.Float).SetInt(v).Float64()
+		return f
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeNumbers(item)
+		}
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeNumbers(item)
+		}
+	}
+	return v
+}
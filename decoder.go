@@ -0,0 +1,53 @@
+package json5
+
+import (
+	"io"
+)
+
+// Decoder reads and decodes JSON5 values from an input stream
+type Decoder struct {
+	p   *parser
+	err error // the error that stopped decoding, returned again by every later call
+}
+
+// NewDecoder returns a new decoder that reads from r.
+// The input is tokenized incrementally, so only the value being decoded is held in memory.
+// Each value is subject to DefaultLimits unless SetOptions sets other limits.
+func NewDecoder(r io.Reader) *Decoder {
+	lex := newLexer(r)
+	lex.opts = withDefaultLimits(ParseOptions{})
+	return &Decoder{p: newParser(lex)}
+}
+
+// SetOptions controls the syntax accepted for the values decoded from now on.
+// If opts.Limits is nil, DefaultLimits apply.
+func (d *Decoder) SetOptions(opts ParseOptions) {
+	d.p.lex.opts = withDefaultLimits(opts)
+}
+
+// Decode reads the next JSON5 value from its input and stores it in the value pointed to by v.
+// Consecutive top-level values are decoded by repeated calls; io.EOF is returned once the input is exhausted.
+// Errors are sticky: the input cannot be resynchronized after one, so later calls return it again.
+func (d *Decoder) Decode(v *interface{}) error {
+	if d.err != nil {
+		return d.err
+	}
+	if d.p.peek().Type == TOKEN_EOF {
+		if d.p.lex.err != nil {
+			return d.p.lex.err
+		}
+		return io.EOF
+	}
+
+	value, err := d.p.parseValue()
+	if err != nil {
+		if d.p.lex.err != nil {
+			err = d.p.lex.err
+		}
+		d.err = err
+		return err
+	}
+	*v = value
+	d.p.lex.resetLimits()
+	return nil
+}
@@ -0,0 +1,83 @@
+package json5
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecoderSingleValue(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(`{name: 'John', tags: ["a", 0x10]}`))
+
+	var v interface{}
+	assert.NoError(t, dec.Decode(&v))
+	assert.Equal(t, map[string]interface{}{"name": "John", "tags": []interface{}{"a", 16}}, v)
+	assert.Equal(t, io.EOF, dec.Decode(&v))
+}
+
+func TestDecoderMultipleValues(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("{a: 1}\n[true, null]\n'text' 42 -7 false"))
+
+	var values []interface{}
+	for {
+		var v interface{}
+		err := dec.Decode(&v)
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		values = append(values, v)
+	}
+
+	expected := []interface{}{
+		map[string]interface{}{"a": 1},
+		[]interface{}{true, nil},
+		"text",
+		42,
+		-7,
+		false,
+	}
+	assert.Equal(t, expected, values)
+}
+
+func TestDecoderDoesNotReadPastValue(t *testing.T) {
+	r, w := io.Pipe()
+	dec := NewDecoder(r)
+
+	go func() {
+		w.Write([]byte(`{a: 1}`))
+	}()
+
+	// The closing brace ends the value, so Decode must return without waiting for more input
+	var v interface{}
+	assert.NoError(t, dec.Decode(&v))
+	assert.Equal(t, map[string]interface{}{"a": 1}, v)
+	w.Close()
+	assert.Equal(t, io.EOF, dec.Decode(&v))
+}
+
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) {
+	return 0, errors.New("read failed")
+}
+
+func TestDecoderReadError(t *testing.T) {
+	dec := NewDecoder(io.MultiReader(strings.NewReader(`{a: `), failingReader{}))
+
+	var v interface{}
+	assert.EqualError(t, dec.Decode(&v), "read failed")
+}
+
+func TestDecoderErrorIsSticky(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(`{a: [1, }] {b: 2}`))
+
+	var v interface{}
+	err := dec.Decode(&v)
+	assert.Error(t, err)
+	assert.Equal(t, err, dec.Decode(&v))
+	assert.Nil(t, v)
+}
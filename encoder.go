@@ -0,0 +1,40 @@
+package json5
+
+import (
+	"bufio"
+	"io"
+)
+
+// Encoder writes JSON5 values to an output stream
+type Encoder struct {
+	w      io.Writer
+	indent string
+	opts   MarshalOptions
+}
+
+// NewEncoder returns a new encoder that writes to w
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// SetIndent sets the string repeated once per nesting level in the output
+func (enc *Encoder) SetIndent(indent string) {
+	enc.indent = indent
+}
+
+// SetOptions controls the output of the values encoded from now on
+func (enc *Encoder) SetOptions(opts MarshalOptions) {
+	enc.opts = opts
+}
+
+// Encode writes the JSON5 form of v to the stream, followed by a newline.
+// The output is produced in a single pass without building intermediate strings.
+func (enc *Encoder) Encode(v interface{}) error {
+	bw := bufio.NewWriter(enc.w)
+	e := &encodeState{w: bw, indent: enc.indent, opts: enc.opts}
+	if err := e.marshalValue(v, 0); err != nil {
+		return err
+	}
+	bw.WriteByte('\n')
+	return bw.Flush()
+}
@@ -0,0 +1,64 @@
+package json5
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncoderMatchesMarshalIndent(t *testing.T) {
+	input := []interface{}{"a", 1, true, nil, []interface{}{2.5, map[string]interface{}{"key": "value"}}}
+
+	expected, err := MarshalIndent(input, "  ")
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.SetIndent("  ")
+	assert.NoError(t, enc.Encode(input))
+	assert.Equal(t, expected+"\n", buf.String())
+}
+
+func TestEncoderMultipleValues(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	assert.NoError(t, enc.Encode("first"))
+	assert.NoError(t, enc.Encode(2))
+	assert.Equal(t, "\"first\"\n2\n", buf.String())
+
+	// The output can be read back with the Decoder
+	dec := NewDecoder(strings.NewReader(buf.String()))
+	var v interface{}
+	assert.NoError(t, dec.Decode(&v))
+	assert.Equal(t, "first", v)
+	assert.NoError(t, dec.Decode(&v))
+	assert.Equal(t, 2, v)
+}
+
+func TestEncoderUnsupportedType(t *testing.T) {
+	var buf bytes.Buffer
+	err := NewEncoder(&buf).Encode(make(chan int))
+	assert.EqualError(t, err, "unsupported type: chan int")
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
+
+func TestEncoderWriteError(t *testing.T) {
+	err := NewEncoder(failingWriter{}).Encode(map[string]interface{}{"a": 1})
+	assert.EqualError(t, err, "write failed")
+}
+
+func TestEncoderSortKeys(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.SetOptions(MarshalOptions{SortKeys: true})
+	assert.NoError(t, enc.Encode(map[string]interface{}{"b": 1, "a": 2}))
+	assert.Equal(t, "{\na: 2,\nb: 1,\n}\n", buf.String())
+}
@@ -0,0 +1,87 @@
+package json5
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors classifying a SyntaxError, for use with errors.Is
+var (
+	ErrUnexpectedEOF       = errors.New("unexpected end of input")
+	ErrUnexpectedToken     = errors.New("unexpected token")
+	ErrUnterminatedString  = errors.New("unterminated string")
+	ErrUnterminatedComment = errors.New("unterminated comment")
+	ErrInvalidEscape       = errors.New("invalid escape sequence")
+	ErrInvalidNumber       = errors.New("invalid number")
+	ErrIntegerRange        = errors.New("integer out of range")
+	ErrExtensionNotAllowed = errors.New("non-standard extension not allowed")
+	ErrJSON5Only           = errors.New("JSON5 feature not allowed in JSON mode")
+	ErrLimitExceeded       = errors.New("limit exceeded")
+	ErrDuplicateKey        = errors.New("duplicate key")
+)
+
+// SyntaxError describes malformed JSON5 input and where it was found.
+// Err is one of the sentinel errors above, so errors.Is(err, ErrUnexpectedEOF) and friends work on it.
+type SyntaxError struct {
+	Position
+	Err      error    // the class of the problem
+	Msg      string   // human readable description
+	Expected []string // what would have been accepted instead, if known
+	Found    string   // the offending token
+	Path     string   // JSON path of the enclosing value, such as $.address.city
+}
+
+// Error returns the description prefixed with "line:column"
+func (e *SyntaxError) Error() string {
+	return e.Position.String() + ": " + e.Msg
+}
+
+// Unwrap returns the sentinel error classifying e
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// LimitError reports input exceeding one of the Limits set in ParseOptions
+type LimitError struct {
+	Position
+	Limit string // name of the Limits field exceeded, such as "MaxDepth"
+	Max   int64  // value of that field
+	Path  string // JSON path of the enclosing value
+}
+
+// limitDescriptions describe what each of the Limits fields bounds
+var limitDescriptions = map[string]string{
+	"MaxDepth":        "nesting depth",
+	"MaxTokens":       "number of tokens",
+	"MaxStringLength": "string length",
+	"MaxMembers":      "number of object members",
+	"MaxInputBytes":   "input size",
+}
+
+// Error returns the description prefixed with "line:column"
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s: %s exceeds %s of %d", e.Position, limitDescriptions[e.Limit], e.Limit, e.Max)
+}
+
+// Unwrap returns ErrLimitExceeded, so errors.Is(err, ErrLimitExceeded) works on it
+func (e *LimitError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// DuplicateKeyError reports an object repeating a key, with DuplicateKeysError set in ParseOptions
+type DuplicateKeyError struct {
+	Position          // position of the repeated key
+	Key      string   // the key
+	First    Position // position of its first occurrence
+	Path     string   // JSON path of the object
+}
+
+// Error returns the description prefixed with "line:column"
+func (e *DuplicateKeyError) Error() string {
+	return fmt.Sprintf("%s: duplicate key '%s', first defined at %s", e.Position, e.Key, e.First)
+}
+
+// Unwrap returns ErrDuplicateKey, so errors.Is(err, ErrDuplicateKey) works on it
+func (e *DuplicateKeyError) Unwrap() error {
+	return ErrDuplicateKey
+}
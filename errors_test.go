@@ -0,0 +1,71 @@
+package json5
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSyntaxErrorAs(t *testing.T) {
+	_, err := UnMarshal("{\n  address: {city: 'York' zip: 1}\n}")
+
+	var syntaxErr *SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("expected a *SyntaxError, got %T", err)
+	}
+	assert.Equal(t, Position{Offset: 27, Line: 2, Column: 26}, syntaxErr.Position)
+	assert.Equal(t, []string{",", "}"}, syntaxErr.Expected)
+	assert.Equal(t, "zip", syntaxErr.Found)
+	assert.Equal(t, "$.address", syntaxErr.Path)
+	assert.True(t, errors.Is(err, ErrUnexpectedToken))
+	assert.EqualError(t, err, "2:26: expected ',' or '}' but found 'zip'")
+}
+
+func TestSyntaxErrorSentinels(t *testing.T) {
+	tests := []struct {
+		input    string
+		sentinel error
+		path     string
+	}{
+		{`{a: [1, 2`, ErrUnexpectedEOF, "$.a"},
+		{`{a: `, ErrUnexpectedEOF, "$.a"},
+		{`["\u{ZZ}"]`, ErrInvalidEscape, "$[0]"},
+		{`"\uZZZZ"`, ErrInvalidEscape, "$"},
+		{`{"odd key": [0, 1e2e3]}`, ErrInvalidNumber, `$["odd key"][1]`},
+		{`{a: ?}`, ErrUnexpectedToken, "$.a"},
+	}
+
+	for _, test := range tests {
+		_, err := UnMarshal(test.input)
+		assert.ErrorIs(t, err, test.sentinel, test.input)
+
+		var syntaxErr *SyntaxError
+		if assert.ErrorAs(t, err, &syntaxErr, test.input) {
+			assert.Equal(t, test.path, syntaxErr.Path, test.input)
+		}
+	}
+}
+
+func TestTokenizeErrorToken(t *testing.T) {
+	tokens := Tokenize(`[1, "bad \u{XYZ}"]`)
+
+	assert.Equal(t, TOKEN_UNKNOWN, tokens[3].Type)
+	assert.ErrorIs(t, tokens[3].Err, ErrInvalidEscape)
+
+	var syntaxErr *SyntaxError
+	if assert.ErrorAs(t, tokens[3].Err, &syntaxErr) {
+		assert.Equal(t, 4, syntaxErr.Offset)
+	}
+}
+
+func TestDecoderSyntaxError(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("[1, 2] {a 1}"))
+
+	var v interface{}
+	assert.NoError(t, dec.Decode(&v))
+	err := dec.Decode(&v)
+	assert.ErrorIs(t, err, ErrUnexpectedToken)
+	assert.EqualError(t, err, "1:11: expected ':' after key 'a' but found '1'")
+}
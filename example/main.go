@@ -62,11 +62,7 @@ func main() {
 
 	fmt.Printf("Parsed result: %+v\n", result4)
 
-	result5, err := json5.UnMarshal(``)
-	if err != nil {
-		fmt.Println("Error:", err)
-		return
-	}
-
-	fmt.Printf("Parsed result: %+v\n", result5)
+	// An empty document is not valid JSON5
+	_, err = json5.UnMarshal(``)
+	fmt.Println("Expected error:", err)
 }
@@ -0,0 +1,163 @@
+//go:build !tinygo
+
+package json5
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// field is an exported struct field, as seen by Unmarshal and Marshal
+type field struct {
+	name      string       // key in the JSON5 object
+	index     []int        // index sequence for reflect.Value.FieldByIndex, through embedded structs
+	typ       reflect.Type // type of the field
+	tagged    bool         // name comes from a json5 or json tag
+	omitEmpty bool         // the ",omitempty" tag option
+	quoted    bool         // the ",string" tag option: a scalar written inside a string
+}
+
+var fieldCache sync.Map // map[reflect.Type][]field
+
+// cachedFields returns the fields of the struct type t, computing them only once per type
+func cachedFields(t reflect.Type) []field {
+	if fields, ok := fieldCache.Load(t); ok {
+		return fields.([]field)
+	}
+	fields, _ := fieldCache.LoadOrStore(t, typeFields(t))
+	return fields.([]field)
+}
+
+// parseTag returns the name and options of the json5 tag of sf, falling back to its json tag
+func parseTag(sf reflect.StructField) (name string, opts []string) {
+	tag, ok := sf.Tag.Lookup("json5")
+	if !ok {
+		tag = sf.Tag.Get("json")
+	}
+	parts := strings.Split(tag, ",")
+	return parts[0], parts[1:]
+}
+
+// typeFields lists the fields of the struct type t in declaration order. Fields of embedded structs
+// are promoted like in encoding/json: the shallowest field of a name wins, and if several are equally
+// shallow the tagged one wins; otherwise the name is ambiguous and left out.
+func typeFields(t reflect.Type) []field {
+	var all []field
+	visited := map[reflect.Type]bool{}
+
+	var walk func(t reflect.Type, index []int)
+	walk = func(t reflect.Type, index []int) {
+		if visited[t] {
+			return
+		}
+		visited[t] = true
+		defer delete(visited, t)
+
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			name, opts := parseTag(sf)
+			if name == "-" && len(opts) == 0 {
+				continue
+			}
+			fieldIndex := append(index[:len(index):len(index)], i)
+
+			ft := sf.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if sf.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+				walk(ft, fieldIndex)
+				continue
+			}
+			if !sf.IsExported() {
+				continue
+			}
+
+			f := field{name: name, index: fieldIndex, typ: sf.Type, tagged: name != ""}
+			if f.name == "" {
+				f.name = sf.Name
+			}
+			for _, opt := range opts {
+				switch opt {
+				case "omitempty":
+					f.omitEmpty = true
+				case "string":
+					switch ft.Kind() {
+					case reflect.Bool, reflect.String,
+						reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+						reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+						reflect.Float32, reflect.Float64:
+						f.quoted = true
+					}
+				}
+			}
+			all = append(all, f)
+		}
+	}
+	walk(t, nil)
+
+	byName := make(map[string][]field)
+	for _, f := range all {
+		byName[f.name] = append(byName[f.name], f)
+	}
+	var fields []field
+	for _, candidates := range byName {
+		if f, ok := dominantField(candidates); ok {
+			fields = append(fields, f)
+		}
+	}
+	sort.Slice(fields, func(i, j int) bool {
+		a, b := fields[i].index, fields[j].index
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
+	return fields
+}
+
+// dominantField picks the field a name refers to among the fields sharing it
+func dominantField(fields []field) (field, bool) {
+	depth := len(fields[0].index)
+	for _, f := range fields[1:] {
+		if len(f.index) < depth {
+			depth = len(f.index)
+		}
+	}
+	var shallowest, tagged []field
+	for _, f := range fields {
+		if len(f.index) != depth {
+			continue
+		}
+		shallowest = append(shallowest, f)
+		if f.tagged {
+			tagged = append(tagged, f)
+		}
+	}
+	switch {
+	case len(shallowest) == 1:
+		return shallowest[0], true
+	case len(tagged) == 1:
+		return tagged[0], true
+	}
+	return field{}, false
+}
+
+// fieldByName finds the field for an object key, preferring an exact match over a case-insensitive one
+func fieldByName(fields []field, key string) (field, bool) {
+	for _, f := range fields {
+		if f.name == key {
+			return f, true
+		}
+	}
+	for _, f := range fields {
+		if strings.EqualFold(f.name, key) {
+			return f, true
+		}
+	}
+	return field{}, false
+}
@@ -0,0 +1,46 @@
+// Package jsonpath writes the JSON paths, such as $.servers[0].host, used by the json5 and ast packages
+// to locate values in errors
+package jsonpath
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Format renders a list of object keys (string) and array indices (int) as a JSON path
+func Format(path []interface{}) string {
+	var sb strings.Builder
+	sb.WriteString("$")
+	for _, elem := range path {
+		switch e := elem.(type) {
+		case int:
+			sb.WriteString("[")
+			sb.WriteString(strconv.Itoa(e))
+			sb.WriteString("]")
+		case string:
+			if IsIdentifier(e) {
+				sb.WriteString(".")
+				sb.WriteString(e)
+			} else {
+				sb.WriteString("[")
+				sb.WriteString(strconv.Quote(e))
+				sb.WriteString("]")
+			}
+		}
+	}
+	return sb.String()
+}
+
+// IsIdentifier reports whether key is a simple identifier, written unquoted as an object key and after
+// a dot in a path
+func IsIdentifier(key string) bool {
+	if len(key) == 0 {
+		return false
+	}
+	for i, ch := range key {
+		if !((ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || ch == '_' || ch == '$' || (i > 0 && ch >= '0' && ch <= '9')) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,86 @@
+package json5
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimits(t *testing.T) {
+	tests := []struct {
+		input    string
+		limits   Limits
+		expected string
+		limit    string
+		path     string
+	}{
+		{`{a: [[1]]}`, Limits{MaxDepth: 2}, "1:6: nesting depth exceeds MaxDepth of 2", "MaxDepth", "$.a[0]"},
+		{`[1, 2, 3]`, Limits{MaxTokens: 5}, "1:8: number of tokens exceeds MaxTokens of 5", "MaxTokens", "$[2]"},
+		{`[1, /* c */ 2]`, Limits{MaxTokens: 3}, "1:5: number of tokens exceeds MaxTokens of 3", "MaxTokens", "$[1]"},
+		{`{name: 'abcdef'}`, Limits{MaxStringLength: 5}, "1:8: string length exceeds MaxStringLength of 5", "MaxStringLength", "$.name"},
+		{`{abcdef: 1}`, Limits{MaxStringLength: 5}, "1:2: string length exceeds MaxStringLength of 5", "MaxStringLength", "$"},
+		{`{a: 1, b: 2, c: 3}`, Limits{MaxMembers: 2}, "1:14: number of object members exceeds MaxMembers of 2", "MaxMembers", "$"},
+		{`[1, 2]`, Limits{MaxInputBytes: 4}, "1:5: input size exceeds MaxInputBytes of 4", "MaxInputBytes", "$[1]"},
+	}
+	for _, test := range tests {
+		_, err := UnMarshalWithOptions(test.input, ParseOptions{Limits: &test.limits})
+		assert.EqualError(t, err, test.expected, test.input)
+		assert.ErrorIs(t, err, ErrLimitExceeded, test.input)
+		var limitErr *LimitError
+		if assert.True(t, errors.As(err, &limitErr), test.input) {
+			assert.Equal(t, test.limit, limitErr.Limit, test.input)
+			assert.Equal(t, test.path, limitErr.Path, test.input)
+		}
+	}
+}
+
+func TestLimitsAtBoundary(t *testing.T) {
+	limits := Limits{MaxDepth: 2, MaxTokens: 7, MaxStringLength: 3, MaxMembers: 1, MaxInputBytes: 14}
+	result, err := UnMarshalWithOptions(`{abc: ['xyz']}`, ParseOptions{Limits: &limits})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"abc": []interface{}{"xyz"}}, result)
+}
+
+func TestDecoderDefaultLimits(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(strings.Repeat("[", 1000000)))
+	var v interface{}
+	err := dec.Decode(&v)
+	assert.EqualError(t, err, "1:1001: nesting depth exceeds MaxDepth of 1000")
+	// The error sticks
+	assert.ErrorIs(t, dec.Decode(&v), ErrLimitExceeded)
+
+	// Without limits, UnMarshal parses deep documents
+	_, err = UnMarshal(strings.Repeat("[", 2000) + strings.Repeat("]", 2000))
+	assert.NoError(t, err)
+}
+
+func TestDecoderLimitsPerValue(t *testing.T) {
+	dec := NewDecoder(strings.NewReader("[1] [2, 3] [4]"))
+	dec.SetOptions(ParseOptions{Limits: &Limits{MaxTokens: 3, MaxInputBytes: 4}})
+	var v interface{}
+	assert.NoError(t, dec.Decode(&v))
+	assert.Equal(t, []interface{}{1}, v)
+	assert.ErrorIs(t, dec.Decode(&v), ErrLimitExceeded)
+
+	dec = NewDecoder(strings.NewReader("[1] [2] [3]"))
+	dec.SetOptions(ParseOptions{Limits: &Limits{MaxTokens: 3, MaxInputBytes: 4}})
+	for i := 1; i <= 3; i++ {
+		assert.NoError(t, dec.Decode(&v))
+		assert.Equal(t, []interface{}{i}, v)
+	}
+	assert.Equal(t, io.EOF, dec.Decode(&v))
+}
+
+func TestReaderDefaultLimits(t *testing.T) {
+	r := NewReader([]byte(strings.Repeat("[", 1001)))
+	assert.EqualError(t, r.Skip(), "1:1001: nesting depth exceeds MaxDepth of 1000")
+}
+
+func TestTokenizeWithLimits(t *testing.T) {
+	tokens := TokenizeWithOptions(`[1, 2, 3]`, ParseOptions{Limits: &Limits{MaxTokens: 2}})
+	assert.Len(t, tokens, 3)
+	assert.ErrorIs(t, tokens[2].Err, ErrLimitExceeded)
+}
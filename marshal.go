@@ -1,138 +1,322 @@
 package json5
 
 import (
+	"encoding"
 	"fmt"
-	"reflect"
+	"io"
+	"math"
+	"math/big"
+	"sort"
 	"strconv"
 	"strings"
+
+	"github.com/shoobyban/json5/internal/jsonpath"
 )
 
 // Marshal converts an interface{} into a JSON5 string.
-func Marshal(value interface{}) (string, error) {
-	return marshalValue(value, "", 0)
+// An optional MarshalOptions controls the output, for example to sort the keys of maps.
+func Marshal(value interface{}, opts ...MarshalOptions) (string, error) {
+	return MarshalIndent(value, "", opts...)
 }
 
 // MarshalIndent converts an interface{} into a JSON5 string with indentation.
-func MarshalIndent(value interface{}, indent string) (string, error) {
-	return marshalValue(value, indent, 0)
+// An optional MarshalOptions controls the output, for example to sort the keys of maps.
+func MarshalIndent(value interface{}, indent string, opts ...MarshalOptions) (string, error) {
+	var sb strings.Builder
+	e := &encodeState{w: &sb, indent: indent}
+	if len(opts) > 0 {
+		e.opts = opts[0]
+	}
+	if err := e.marshalValue(value, 0); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// Marshaler is implemented by types that write their own JSON5 form.
+// MarshalJSON5 must return a single valid JSON5 value, which is written as is.
+type Marshaler interface {
+	MarshalJSON5() ([]byte, error)
+}
+
+// writer is the subset of strings.Builder and bufio.Writer the encoder writes to
+type writer interface {
+	io.Writer
+	io.StringWriter
+	io.ByteWriter
+}
+
+// encodeState writes the JSON5 form of Go values to w in a single pass
+type encodeState struct {
+	w      writer
+	indent string
+	opts   MarshalOptions
+}
+
+// stringReplacer escapes the characters that cannot appear verbatim in a quoted string, control
+// characters included so that the output is valid JSON as well
+var stringReplacer = strings.NewReplacer(append([]string{
+	"\\", "\\\\",
+	"\"", "\\\"",
+	"\n", "\\n",
+	"\r", "\\r",
+	"\t", "\\t",
+	"\b", "\\b",
+	"\f", "\\f",
+}, controlEscapes()...)...)
+
+// controlEscapes returns the \uXXXX escapes of the control characters without a shorter one
+func controlEscapes() []string {
+	var pairs []string
+	for c := rune(0); c < 0x20; c++ {
+		switch c {
+		case '\n', '\r', '\t', '\b', '\f':
+			continue
+		}
+		pairs = append(pairs, string(c), fmt.Sprintf("\\u%04x", c))
+	}
+	return pairs
 }
 
-// marshalValue recursively converts a Go value into a JSON5 string
-func marshalValue(value interface{}, indent string, depth int) (string, error) {
+// marshalValue recursively writes a Go value as JSON5
+func (e *encodeState) marshalValue(value interface{}, depth int) error {
 	switch v := value.(type) {
 	case nil:
-		return "null", nil
+		e.w.WriteString("null")
 	case bool:
 		if v {
-			return "true", nil
+			e.w.WriteString("true")
+		} else {
+			e.w.WriteString("false")
+		}
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		fmt.Fprintf(e.w, "%v", v)
+	case *big.Int:
+		if v == nil {
+			e.w.WriteString("null")
+		} else {
+			e.w.WriteString(v.String())
 		}
-		return "false", nil
-	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
-		return fmt.Sprintf("%v", v), nil
+	case float32:
+		e.marshalFloat(float64(v), v)
+	case float64:
+		e.marshalFloat(v, v)
 	case string:
-		return marshalString(v), nil
+		e.marshalString(v)
+	case Number:
+		if !isNumberLiteral(string(v)) {
+			return fmt.Errorf("json5: invalid number literal %q", string(v))
+		}
+		e.w.WriteString(string(v))
 	case []interface{}:
-		return marshalArray(v, indent, depth)
+		return e.marshalArray(v, depth)
 	case map[string]interface{}:
-		return marshalObject(v, indent, depth)
+		return e.marshalObject(v, depth)
+	case *Object:
+		return e.marshalOrderedObject(v, depth)
+	case Object:
+		return e.marshalOrderedObject(&v, depth)
+	// Common concrete collections are written without reflection, so they also work on TinyGo
+	case []string:
+		return marshalSlice(e, v, depth, func(s string) error {
+			e.marshalString(s)
+			return nil
+		})
+	case []int:
+		return marshalSlice(e, v, depth, func(n int) error {
+			e.w.WriteString(strconv.Itoa(n))
+			return nil
+		})
+	case []float64:
+		return marshalSlice(e, v, depth, func(f float64) error {
+			e.marshalFloat(f, f)
+			return nil
+		})
+	case []map[string]interface{}:
+		return marshalSlice(e, v, depth, func(obj map[string]interface{}) error {
+			return e.marshalObject(obj, depth+1)
+		})
+	case map[string]string:
+		return marshalMap(e, v, depth, func(s string) error {
+			e.marshalString(s)
+			return nil
+		})
+	case map[string]int:
+		return marshalMap(e, v, depth, func(n int) error {
+			e.w.WriteString(strconv.Itoa(n))
+			return nil
+		})
 	default:
-		// Handle other types if needed (custom types, etc.)
-		return "", fmt.Errorf("unsupported type: %v", reflect.TypeOf(value))
+		// Structs, other typed slices and maps, pointers and named types
+		return e.marshalOther(value, depth)
+	}
+	return nil
+}
+
+// marshalRaw writes the output of a MarshalJSON5 or MarshalJSON method after checking it is a single JSON5 value.
+// The value is written anew at depth rather than copied, so that it is laid out like the rest of the output;
+// its key order and number literals are kept.
+func (e *encodeState) marshalRaw(data []byte, err error, method string, value interface{}, depth int) error {
+	if err != nil {
+		return fmt.Errorf("json5: error calling %s for type %T: %w", method, value, err)
+	}
+	parsed, err := UnMarshalWithOptions(string(data), ParseOptions{OrderedObjects: true, UseNumber: true})
+	if err != nil {
+		return fmt.Errorf("json5: error calling %s for type %T: %w", method, value, err)
+	}
+	return e.marshalValue(parsed, depth)
+}
+
+// marshalText writes the output of a MarshalText method as a string
+func (e *encodeState) marshalText(m encoding.TextMarshaler) error {
+	text, err := m.MarshalText()
+	if err != nil {
+		return fmt.Errorf("json5: error calling MarshalText for type %T: %w", m, err)
+	}
+	e.marshalString(string(text))
+	return nil
+}
+
+// marshalFloat writes a float, using the JSON5 literals for infinities and NaN.
+// The original value is formatted so float32 keeps its shortest representation.
+func (e *encodeState) marshalFloat(f float64, original interface{}) {
+	switch {
+	case math.IsInf(f, 1):
+		e.w.WriteString("Infinity")
+	case math.IsInf(f, -1):
+		e.w.WriteString("-Infinity")
+	case math.IsNaN(f):
+		e.w.WriteString("NaN")
+	default:
+		fmt.Fprintf(e.w, "%v", original)
 	}
 }
 
 // marshalString handles string values and escapes necessary characters
-func marshalString(s string) string {
-	// Escape necessary characters in the string
-	replacer := strings.NewReplacer(
-		"\\", "\\\\",
-		"\"", "\\\"",
-		"\n", "\\n",
-		"\r", "\\r",
-		"\t", "\\t",
-	)
-	escaped := replacer.Replace(s)
-	return fmt.Sprintf("\"%s\"", escaped)
-}
-
-// marshalArray handles slices of interface{} and converts them into JSON5 arrays
-func marshalArray(array []interface{}, indent string, depth int) (string, error) {
-	var sb strings.Builder
-	sb.WriteString("[")
+func (e *encodeState) marshalString(s string) {
+	e.w.WriteByte('"')
+	stringReplacer.WriteString(e.w, s)
+	e.w.WriteByte('"')
+}
 
-	newIndent := strings.Repeat(indent, depth+1)
-	first := true
+// writeIndent starts a new line indented to the given depth
+func (e *encodeState) writeIndent(depth int) {
+	e.w.WriteByte('\n')
+	for i := 0; i < depth; i++ {
+		e.w.WriteString(e.indent)
+	}
+}
 
-	for _, item := range array {
-		if !first {
-			sb.WriteString(", ")
-		}
-		first = false
+// writeArray writes a JSON5 array of n items, calling item to write the i-th one
+func (e *encodeState) writeArray(n int, depth int, item func(i int) error) error {
+	e.w.WriteByte('[')
 
-		itemStr, err := marshalValue(item, indent, depth+1)
-		if err != nil {
-			return "", err
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			e.w.WriteString(", ")
 		}
+		e.writeIndent(depth + 1)
+		if err := item(i); err != nil {
+			return err
+		}
+	}
+
+	e.writeIndent(depth)
+	e.w.WriteByte(']')
+	return nil
+}
 
-		sb.WriteString("\n")
-		sb.WriteString(newIndent)
-		sb.WriteString(itemStr)
+// writeObject writes a JSON5 object with the given keys, calling value to write the value of each of them
+func (e *encodeState) writeObject(keys []string, depth int, value func(key string) error) error {
+	e.w.WriteByte('{')
+
+	for _, key := range keys {
+		e.writeIndent(depth + 1)
+		e.w.WriteString(marshalKey(key))
+		e.w.WriteString(": ")
+		if err := value(key); err != nil {
+			return err
+		}
+		e.w.WriteByte(',')
 	}
 
-	sb.WriteString("\n")
-	sb.WriteString(strings.Repeat(indent, depth))
-	sb.WriteString("]")
-	return sb.String(), nil
+	e.writeIndent(depth)
+	e.w.WriteByte('}')
+	return nil
 }
 
-// marshalObject handles maps and converts them into JSON5 objects
-func marshalObject(obj map[string]interface{}, indent string, depth int) (string, error) {
-	var sb strings.Builder
-	sb.WriteString("{")
+// marshalArray handles slices of interface{} and writes them as JSON5 arrays
+func (e *encodeState) marshalArray(array []interface{}, depth int) error {
+	return e.writeArray(len(array), depth, func(i int) error {
+		return e.marshalValue(array[i], depth+1)
+	})
+}
+
+// marshalObject handles maps and writes them as JSON5 objects
+func (e *encodeState) marshalObject(obj map[string]interface{}, depth int) error {
+	return e.writeObject(e.sortKeys(mapKeys(obj), false), depth, func(key string) error {
+		return e.marshalValue(obj[key], depth+1)
+	})
+}
 
-	newIndent := strings.Repeat(indent, depth+1)
+// marshalOrderedObject writes an *Object as a JSON5 object, keeping the order of its keys
+func (e *encodeState) marshalOrderedObject(obj *Object, depth int) error {
+	return e.writeObject(obj.Keys(), depth, func(key string) error {
+		value, _ := obj.Get(key)
+		return e.marshalValue(value, depth+1)
+	})
+}
 
-	for key, value := range obj {
-		keyStr := marshalKey(key)
+// marshalSlice writes a typed slice as a JSON5 array, or null when it is nil like other typed slices
+func marshalSlice[T any](e *encodeState, items []T, depth int, write func(T) error) error {
+	if items == nil {
+		e.w.WriteString("null")
+		return nil
+	}
+	return e.writeArray(len(items), depth, func(i int) error {
+		return write(items[i])
+	})
+}
 
-		valueStr, err := marshalValue(value, indent, depth+1)
-		if err != nil {
-			return "", err
-		}
+// marshalMap writes a typed map as a JSON5 object with sorted keys, or null when it is nil like other typed maps
+func marshalMap[V any](e *encodeState, m map[string]V, depth int, write func(V) error) error {
+	if m == nil {
+		e.w.WriteString("null")
+		return nil
+	}
+	return e.writeObject(e.sortKeys(mapKeys(m), true), depth, func(key string) error {
+		return write(m[key])
+	})
+}
 
-		sb.WriteString("\n")
-		sb.WriteString(newIndent)
-		sb.WriteString(keyStr)
-		sb.WriteString(": ")
-		sb.WriteString(valueStr)
-		sb.WriteString(",")
+// mapKeys returns the keys of m in map iteration order
+func mapKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
 	}
+	return keys
+}
 
-	sb.WriteString("\n")
-	sb.WriteString(strings.Repeat(indent, depth))
-	sb.WriteString("}")
-	return sb.String(), nil
+// sortKeys sorts the keys of a map when MarshalOptions asks for it, or always if force is set
+func (e *encodeState) sortKeys(keys []string, force bool) []string {
+	if !force && !e.opts.SortKeys && e.opts.KeyLess == nil {
+		return keys
+	}
+	less := e.opts.KeyLess
+	if less == nil {
+		less = func(a, b string) bool { return a < b }
+	}
+	sort.Slice(keys, func(i, j int) bool { return less(keys[i], keys[j]) })
+	return keys
 }
 
 // marshalKey checks if a key can be unquoted in JSON5 (simple identifier) or must be quoted
 func marshalKey(key string) string {
 	// Check if the key can be unquoted (simple identifier rules for JSON5)
-	if isSimpleIdentifier(key) {
+	if jsonpath.IsIdentifier(key) {
 		return key
 	}
-	// Otherwise, quote the key
-	return strconv.Quote(key)
-}
-
-// isSimpleIdentifier checks if a string qualifies as a simple identifier (unquoted in JSON5)
-func isSimpleIdentifier(key string) bool {
-	if len(key) == 0 {
-		return false
-	}
-	for i, ch := range key {
-		if !((ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || ch == '_' || ch == '$' || (i > 0 && ch >= '0' && ch <= '9')) {
-			return false
-		}
-	}
-	return true
+	// Otherwise, quote the key like a string value
+	return `"` + stringReplacer.Replace(key) + `"`
 }
@@ -0,0 +1,211 @@
+//go:build !tinygo
+
+package json5
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+var textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// marshalOther writes the values marshalValue does not handle itself, using reflection
+func (e *encodeState) marshalOther(value interface{}, depth int) error {
+	return e.marshalReflect(reflect.ValueOf(value), depth)
+}
+
+// marshalReflect writes the values marshalValue does not handle itself: types implementing WriteJSON5, Marshaler,
+// json.Marshaler or encoding.TextMarshaler, structs, typed slices, arrays and maps, pointers,
+// interfaces and named types, following the json5 and json struct tags
+func (e *encodeState) marshalReflect(v reflect.Value, depth int) error {
+	if v.Kind() == reflect.Pointer && v.IsNil() {
+		e.w.WriteString("null")
+		return nil
+	}
+	if v.IsValid() && v.CanInterface() {
+		switch m := v.Interface().(type) {
+		case writerTo:
+			return e.writeTo(m, depth)
+		case Marshaler:
+			data, err := m.MarshalJSON5()
+			return e.marshalRaw(data, err, "MarshalJSON5", m, depth)
+		case json.Marshaler:
+			data, err := m.MarshalJSON()
+			return e.marshalRaw(data, err, "MarshalJSON", m, depth)
+		case encoding.TextMarshaler:
+			return e.marshalText(m)
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Invalid:
+		e.w.WriteString("null")
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			e.w.WriteString("null")
+			return nil
+		}
+		return e.marshalValue(v.Elem().Interface(), depth)
+	case reflect.Bool:
+		return e.marshalValue(v.Bool(), depth)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.w.WriteString(strconv.FormatInt(v.Int(), 10))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.w.WriteString(strconv.FormatUint(v.Uint(), 10))
+	case reflect.Float32:
+		e.marshalFloat(v.Float(), float32(v.Float()))
+	case reflect.Float64:
+		e.marshalFloat(v.Float(), v.Float())
+	case reflect.String:
+		e.marshalString(v.String())
+	case reflect.Slice:
+		if v.IsNil() {
+			e.w.WriteString("null")
+			return nil
+		}
+		return e.marshalReflectArray(v, depth)
+	case reflect.Array:
+		return e.marshalReflectArray(v, depth)
+	case reflect.Map:
+		if v.IsNil() {
+			e.w.WriteString("null")
+			return nil
+		}
+		return e.marshalReflectMap(v, depth)
+	case reflect.Struct:
+		return e.marshalStruct(v, depth)
+	default:
+		return fmt.Errorf("unsupported type: %v", v.Type())
+	}
+	return nil
+}
+
+// marshalReflectArray writes a typed slice or an array as a JSON5 array
+func (e *encodeState) marshalReflectArray(v reflect.Value, depth int) error {
+	items := make([]interface{}, v.Len())
+	for i := range items {
+		items[i] = v.Index(i).Interface()
+	}
+	return e.marshalArray(items, depth)
+}
+
+// marshalReflectMap writes a typed map as a JSON5 object. Keys can be strings, integers or
+// encoding.TextMarshaler implementations; they are sorted so the output is deterministic.
+func (e *encodeState) marshalReflectMap(v reflect.Value, depth int) error {
+	keys := make([]string, 0, v.Len())
+	values := make(map[string]interface{}, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		key, err := mapKeyString(iter.Key())
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key)
+		values[key] = iter.Value().Interface()
+	}
+
+	return e.writeObject(e.sortKeys(keys, true), depth, func(key string) error {
+		return e.marshalValue(values[key], depth+1)
+	})
+}
+
+// mapKeyString converts a map key into an object key
+func mapKeyString(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if k.Type().Implements(textMarshalerType) {
+		if k.Kind() == reflect.Pointer && k.IsNil() {
+			return "", nil
+		}
+		text, err := k.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(text), err
+	}
+	switch k.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(k.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return strconv.FormatUint(k.Uint(), 10), nil
+	}
+	return "", fmt.Errorf("unsupported map key type: %v", k.Type())
+}
+
+// marshalStruct writes the exported fields of a struct as a JSON5 object, in declaration order
+func (e *encodeState) marshalStruct(v reflect.Value, depth int) error {
+	var keys []string
+	values := make(map[string]reflect.Value)
+	quoted := make(map[string]bool)
+	for _, f := range cachedFields(v.Type()) {
+		fv, ok := structField(v, f.index)
+		if !ok || (f.omitEmpty && isEmptyValue(fv)) {
+			continue
+		}
+		keys = append(keys, f.name)
+		values[f.name] = fv
+		quoted[f.name] = f.quoted
+	}
+
+	return e.writeObject(keys, depth, func(key string) error {
+		if quoted[key] {
+			s, err := e.quote(values[key])
+			if err != nil {
+				return err
+			}
+			return e.marshalValue(s, depth+1)
+		}
+		return e.marshalValue(values[key].Interface(), depth+1)
+	})
+}
+
+// structField returns the field of struct v at index, or false if it is behind a nil embedded pointer
+func structField(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// quote returns the JSON5 form of a scalar field tagged with ",string", to be written inside a string
+func (e *encodeState) quote(v reflect.Value) (interface{}, error) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	var sb strings.Builder
+	inner := &encodeState{w: &sb, opts: e.opts}
+	if err := inner.marshalValue(v.Interface(), 0); err != nil {
+		return nil, err
+	}
+	return sb.String(), nil
+}
+
+// isEmptyValue reports whether v is empty for the ",omitempty" tag option, as in encoding/json
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	}
+	return false
+}
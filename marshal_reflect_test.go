@@ -0,0 +1,170 @@
+//go:build !tinygo
+
+package json5
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testLevel int
+
+func (l testLevel) MarshalText() ([]byte, error) {
+	return []byte(strings.Repeat("*", int(l))), nil
+}
+
+type testMeta struct {
+	Created string `json5:"created"`
+	Owner   string `json5:"owner,omitempty"`
+}
+
+type testServer struct {
+	*testMeta
+	Name    string               `json5:"name"`
+	Port    int                  `json5:"port,string"`
+	Hosts   []string             `json:"hosts"`
+	Weights map[string]float64   `json5:"weights,omitempty"`
+	Levels  map[testLevel]bool   `json5:"levels,omitempty"`
+	Ports   map[uint16]string    `json5:"ports,omitempty"`
+	Backup  *testServer          `json5:"backup,omitempty"`
+	Extra   interface{}          `json5:"extra"`
+	Secret  string               `json5:"-"`
+	Dash    string               `json5:"-,"`
+	Limits  [2]int               `json5:"limits"`
+	Nested  map[string][]float32 `json5:"nested,omitempty"`
+	private int
+}
+
+func TestMarshalStruct(t *testing.T) {
+	server := testServer{
+		testMeta: &testMeta{Created: "today"},
+		Name:     "web",
+		Port:     8080,
+		Hosts:    []string{"a", "b"},
+		Weights:  map[string]float64{"b": 0.5, "a": 1},
+		Levels:   map[testLevel]bool{2: true, 1: false},
+		Ports:    map[uint16]string{443: "https", 80: "http"},
+		Backup:   &testServer{Name: "spare"},
+		Extra:    []int{1},
+		Secret:   "hidden",
+		Dash:     "shown",
+		Limits:   [2]int{1, 2},
+		Nested:   map[string][]float32{"x": {0.1}},
+		private:  1,
+	}
+
+	result, err := MarshalIndent(server, "  ")
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+  created: "today",
+  name: "web",
+  port: "8080",
+  hosts: [
+    "a", 
+    "b"
+  ],
+  weights: {
+    a: 1,
+    b: 0.5,
+  },
+  levels: {
+    "*": false,
+    "**": true,
+  },
+  ports: {
+    "443": "https",
+    "80": "http",
+  },
+  backup: {
+    name: "spare",
+    port: "0",
+    hosts: null,
+    extra: null,
+    "-": "",
+    limits: [
+      0, 
+      0
+    ],
+  },
+  extra: [
+    1
+  ],
+  "-": "shown",
+  limits: [
+    1, 
+    2
+  ],
+  nested: {
+    x: [
+      0.1
+    ],
+  },
+}`, result)
+}
+
+func TestMarshalReflectTypes(t *testing.T) {
+	type mode string
+	type point struct{ X, Y int }
+	n := 3
+	var nilPtr *point
+
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{[]string{"a", "b"}, "[\n\"a\", \n\"b\"\n]"},
+		{[]int(nil), "null"},
+		{map[string]int{"b": 2, "a": 1}, "{\na: 1,\nb: 2,\n}"},
+		{map[int]string{10: "x", 9: "y"}, "{\n\"10\": \"x\",\n\"9\": \"y\",\n}"},
+		{mode("fast"), `"fast"`},
+		{&n, "3"},
+		{nilPtr, "null"},
+		{point{1, 2}, "{\nX: 1,\nY: 2,\n}"},
+		{[]*point{{X: 1}, nil}, "[\n{\nX: 1,\nY: 0,\n}, \nnull\n]"},
+		{float32(0.1), "0.1"},
+	}
+	for _, test := range tests {
+		result, err := Marshal(test.value)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, result)
+	}
+}
+
+func TestMarshalStructRoundTrip(t *testing.T) {
+	in := testServer{testMeta: &testMeta{Created: "now", Owner: "me"}, Name: "db", Port: 5432, Hosts: []string{"h"}}
+	out, err := Marshal(in)
+	assert.NoError(t, err)
+
+	// An embedded pointer to an unexported struct cannot be allocated by Unmarshal
+	var decoded testServer
+	assert.EqualError(t, Unmarshal([]byte(out), &decoded), "json5: cannot set embedded pointer to unexported struct json5.testMeta")
+
+	decoded = testServer{testMeta: &testMeta{}}
+	assert.NoError(t, Unmarshal([]byte(out), &decoded))
+	assert.Equal(t, in, decoded)
+}
+
+func TestMarshalUnsupportedTypes(t *testing.T) {
+	_, err := Marshal(map[string]interface{}{"f": func() {}})
+	assert.EqualError(t, err, "unsupported type: func()")
+
+	_, err = Marshal(map[[2]int]string{{1, 2}: "x"})
+	assert.EqualError(t, err, "unsupported map key type: [2]int")
+}
+
+func TestMarshalNumberFields(t *testing.T) {
+	type account struct {
+		ID      Number `json5:"id"`
+		Balance Number `json5:"balance,string"`
+	}
+	in := []byte(`{id: 123456789012345678901, balance: "0.10"}`)
+	var a account
+	assert.NoError(t, UnmarshalWithOptions(in, &a, ParseOptions{UseNumber: true}))
+	assert.Equal(t, account{ID: "123456789012345678901", Balance: "0.10"}, a)
+
+	out, err := Marshal(a)
+	assert.NoError(t, err)
+	assert.Equal(t, "{\nid: 123456789012345678901,\nbalance: \"0.10\",\n}", out)
+}
@@ -1,6 +1,7 @@
 package json5
 
 import (
+	"math"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -52,14 +53,14 @@ null
 }
 
 func TestMarshalObject(t *testing.T) {
-	input := map[string]interface{}{
-		"name": "John Doe",
-		"age":  42,
-		"address": map[string]interface{}{
-			"city":    "New York",
-			"zipcode": 10001,
-		},
-	}
+	// Keys are written in insertion order, which a map cannot keep
+	address := NewObject()
+	address.Set("city", "New York")
+	address.Set("zipcode", 10001)
+	input := NewObject()
+	input.Set("name", "John Doe")
+	input.Set("age", 42)
+	input.Set("address", address)
 	expected := `{
 name: "John Doe",
 age: 42,
@@ -74,12 +75,11 @@ zipcode: 10001,
 }
 
 func TestMarshalComplexObject(t *testing.T) {
-	input := map[string]interface{}{
-		"simpleKey": "value",
-		"complex key": map[string]interface{}{
-			"nestedKey": "nestedValue",
-		},
-	}
+	nested := NewObject()
+	nested.Set("nestedKey", "nestedValue")
+	input := NewObject()
+	input.Set("simpleKey", "value")
+	input.Set("complex key", nested)
 	expected := `{
 simpleKey: "value",
 "complex key": {
@@ -90,3 +90,119 @@ nestedKey: "nestedValue",
 	assert.NoError(t, err)
 	assert.Equal(t, expected, result)
 }
+
+func TestMarshalSpecialFloats(t *testing.T) {
+	input := []interface{}{math.Inf(1), math.Inf(-1), math.NaN(), float32(math.Inf(1)), float32(0.1)}
+	expected := `[
+Infinity, 
+-Infinity, 
+NaN, 
+Infinity, 
+0.1
+]`
+	result, err := Marshal(input)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+
+	// The output parses back to the same values
+	parsed, err := UnMarshal(result)
+	assert.NoError(t, err)
+	values := parsed.([]interface{})
+	assert.Equal(t, math.Inf(1), values[0])
+	assert.Equal(t, math.Inf(-1), values[1])
+	assert.True(t, math.IsNaN(values[2].(float64)))
+}
+
+func TestMarshalStringControlCharacters(t *testing.T) {
+	input := "a\b\f\"\\"
+	result, err := Marshal(input)
+	assert.NoError(t, err)
+	assert.Equal(t, `"a\b\f\"\\"`, result)
+
+	parsed, err := UnMarshal(result)
+	assert.NoError(t, err)
+	assert.Equal(t, input, parsed)
+}
+
+func TestMarshalKeyEscapes(t *testing.T) {
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{"a\x07", "{\n\"a\\u0007\": 1,\n}"},
+		{"\x00\n", "{\n\"\\u0000\\n\": 1,\n}"},
+		{"\U000f0000", "{\n\"\U000f0000\": 1,\n}"},
+		{"😀 \"x\"", "{\n\"😀 \\\"x\\\"\": 1,\n}"},
+	}
+	for _, test := range tests {
+		input := map[string]interface{}{test.key: 1}
+		result, err := Marshal(input)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, result)
+
+		parsed, err := UnMarshal(result)
+		assert.NoError(t, err)
+		assert.Equal(t, input, parsed)
+	}
+}
+
+func TestMarshalMap(t *testing.T) {
+	input := map[string]interface{}{
+		"address": map[string]interface{}{"city": "New York"},
+	}
+	expected := `{
+address: {
+city: "New York",
+},
+}`
+	result, err := Marshal(input)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+}
+
+func TestMarshalSortKeys(t *testing.T) {
+	input := map[string]interface{}{
+		"name": "John Doe",
+		"age":  42,
+		"address": map[string]interface{}{
+			"zipcode": 10001,
+			"city":    "New York",
+		},
+	}
+	expected := `{
+  address: {
+    city: "New York",
+    zipcode: 10001,
+  },
+  age: 42,
+  name: "John Doe",
+}`
+	for i := 0; i < 10; i++ {
+		result, err := MarshalIndent(input, "  ", MarshalOptions{SortKeys: true})
+		assert.NoError(t, err)
+		assert.Equal(t, expected, result)
+	}
+
+	result, err := Marshal(map[string]interface{}{"b": 1, "a": 2, "c": 3}, MarshalOptions{SortKeys: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "{\na: 2,\nb: 1,\nc: 3,\n}", result)
+}
+
+func TestMarshalKeyLess(t *testing.T) {
+	input := map[string]interface{}{"bb": 1, "a": 2, "ccc": 3}
+	byLength := func(a, b string) bool { return len(a) > len(b) }
+
+	result, err := Marshal(input, MarshalOptions{KeyLess: byLength})
+	assert.NoError(t, err)
+	assert.Equal(t, "{\nccc: 3,\nbb: 1,\na: 2,\n}", result)
+}
+
+func TestMarshalSortKeysKeepsObjectOrder(t *testing.T) {
+	obj := NewObject()
+	obj.Set("b", 1)
+	obj.Set("a", map[string]interface{}{"y": 1, "x": 2})
+
+	result, err := Marshal(obj, MarshalOptions{SortKeys: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "{\nb: 1,\na: {\nx: 2,\ny: 1,\n},\n}", result)
+}
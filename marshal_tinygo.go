@@ -0,0 +1,23 @@
+//go:build tinygo
+
+package json5
+
+import (
+	"encoding"
+	"fmt"
+)
+
+// marshalOther writes types implementing WriteJSON5, Marshaler or encoding.TextMarshaler, and rejects the other
+// values marshalValue does not handle itself, as TinyGo has no full reflection support
+func (e *encodeState) marshalOther(value interface{}, depth int) error {
+	switch m := value.(type) {
+	case writerTo:
+		return e.writeTo(m, depth)
+	case Marshaler:
+		data, err := m.MarshalJSON5()
+		return e.marshalRaw(data, err, "MarshalJSON5", m, depth)
+	case encoding.TextMarshaler:
+		return e.marshalText(m)
+	}
+	return fmt.Errorf("unsupported type: %T", value)
+}
@@ -0,0 +1,248 @@
+//go:build !tinygo
+
+package json5
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testDuration is written as a string such as "1m30s"
+type testDuration time.Duration
+
+func (d testDuration) MarshalJSON5() ([]byte, error) {
+	return []byte(`'` + time.Duration(d).String() + `'`), nil
+}
+
+func (d *testDuration) UnmarshalJSON5(data []byte) error {
+	var s string
+	if err := Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = testDuration(parsed)
+	return nil
+}
+
+// testIPNet is written as a CIDR string through encoding.TextMarshaler
+type testIPNet struct {
+	net.IPNet
+}
+
+func (n testIPNet) MarshalText() ([]byte, error) {
+	return []byte(n.String()), nil
+}
+
+func (n *testIPNet) UnmarshalText(text []byte) error {
+	_, ipNet, err := net.ParseCIDR(string(text))
+	if err != nil {
+		return err
+	}
+	n.IPNet = *ipNet
+	return nil
+}
+
+// testColor is an enum written by name
+type testColor int
+
+const (
+	testRed testColor = iota
+	testGreen
+)
+
+var testColorNames = []string{"red", "green"}
+
+func (c testColor) MarshalText() ([]byte, error) {
+	return []byte(testColorNames[c]), nil
+}
+
+func (c *testColor) UnmarshalText(text []byte) error {
+	for i, name := range testColorNames {
+		if name == string(text) {
+			*c = testColor(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown color %q", text)
+}
+
+// testJSONPoint only implements json.Marshaler
+type testJSONPoint struct{ X, Y int }
+
+func (p testJSONPoint) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf(`[%d, %d]`, p.X, p.Y)), nil
+}
+
+type testNetConfig struct {
+	Timeout  testDuration          `json5:"timeout"`
+	Retry    *testDuration         `json5:"retry,omitempty"`
+	Subnet   testIPNet             `json5:"subnet"`
+	Color    testColor             `json5:"color"`
+	Palette  map[testColor]int     `json5:"palette"`
+	Backoffs []testDuration        `json5:"backoffs"`
+	Named    map[string]*testColor `json5:"named,omitempty"`
+}
+
+func TestMarshalerRoundTrip(t *testing.T) {
+	_, subnet, _ := net.ParseCIDR("10.0.0.0/8")
+	cfg := testNetConfig{
+		Timeout:  testDuration(90 * time.Second),
+		Subnet:   testIPNet{*subnet},
+		Color:    testGreen,
+		Palette:  map[testColor]int{testRed: 1, testGreen: 2},
+		Backoffs: []testDuration{testDuration(time.Second)},
+	}
+
+	out, err := MarshalIndent(cfg, "  ")
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+  timeout: "1m30s",
+  subnet: "10.0.0.0/8",
+  color: "green",
+  palette: {
+    green: 2,
+    red: 1,
+  },
+  backoffs: [
+    "1s"
+  ],
+}`, out)
+
+	var decoded testNetConfig
+	assert.NoError(t, Unmarshal([]byte(out), &decoded))
+	assert.Equal(t, cfg, decoded)
+}
+
+func TestUnmarshalerNull(t *testing.T) {
+	retry := testDuration(time.Second)
+	cfg := testNetConfig{Timeout: testDuration(time.Minute), Retry: &retry}
+	assert.NoError(t, Unmarshal([]byte(`{timeout: null, retry: null}`), &cfg))
+	assert.Equal(t, testDuration(time.Minute), cfg.Timeout)
+	assert.Nil(t, cfg.Retry)
+
+	assert.NoError(t, Unmarshal([]byte(`{retry: '2s'}`), &cfg))
+	assert.Equal(t, testDuration(2*time.Second), *cfg.Retry)
+}
+
+func TestUnmarshalerErrors(t *testing.T) {
+	var cfg testNetConfig
+	tests := []struct {
+		input string
+		msg   string
+	}{
+		{`{timeout: 'soon'}`, `$.timeout: time: invalid duration "soon"`},
+		{`{timeout: 5}`, "$: cannot unmarshal number 5 into Go value of type string"},
+		{`{color: 'blue'}`, `$.color: unknown color "blue"`},
+		{`{color: 1}`, "$.color: cannot unmarshal number 1 into Go value of type json5.testColor"},
+		{`{palette: {blue: 1}}`, `$.palette.blue: unknown color "blue"`},
+		{`{subnet: 'x'}`, "$.subnet: invalid CIDR address: x"},
+	}
+	for _, test := range tests {
+		err := Unmarshal([]byte(test.input), &cfg)
+		assert.EqualError(t, err, test.msg, test.input)
+	}
+}
+
+func TestMarshalJSONMarshaler(t *testing.T) {
+	out, err := Marshal(map[string]interface{}{"origin": testJSONPoint{1, 2}})
+	assert.NoError(t, err)
+	assert.Equal(t, "{\norigin: [\n1, \n2\n],\n}", out)
+}
+
+// testLayout writes itself on several lines, in a layout of its own
+type testLayout struct{}
+
+func (testLayout) MarshalJSON5() ([]byte, error) {
+	return []byte("{\n\t\tz: 0x1F,   // hex\n\t\ta: [1,\n2],\n}"), nil
+}
+
+func TestMarshalerIndent(t *testing.T) {
+	out, err := MarshalIndent(map[string]interface{}{"layout": testLayout{}}, "  ")
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+  layout: {
+    z: 0x1F,
+    a: [
+      1, 
+      2
+    ],
+  },
+}`, out)
+}
+
+type testBadMarshaler struct {
+	out string
+	err error
+}
+
+func (m testBadMarshaler) MarshalJSON5() ([]byte, error) {
+	return []byte(m.out), m.err
+}
+
+func TestMarshalerErrors(t *testing.T) {
+	failure := errors.New("boom")
+	_, err := Marshal([]interface{}{testBadMarshaler{err: failure}})
+	assert.EqualError(t, err, "json5: error calling MarshalJSON5 for type json5.testBadMarshaler: boom")
+	assert.True(t, errors.Is(err, failure))
+
+	_, err = Marshal(testBadMarshaler{out: "{a: "})
+	assert.True(t, errors.Is(err, ErrUnexpectedEOF))
+	assert.True(t, strings.HasPrefix(err.Error(), "json5: error calling MarshalJSON5 for type json5.testBadMarshaler: "))
+
+	var nilDuration *testDuration
+	out, err := Marshal(nilDuration)
+	assert.NoError(t, err)
+	assert.Equal(t, "null", out)
+}
+
+func TestUnmarshalReaderFromPath(t *testing.T) {
+	var v struct {
+		Points []point `json5:"points"`
+	}
+	err := Unmarshal([]byte(`{points: [{x: 1}, {y: 'a'}]}`), &v)
+	assert.EqualError(t, err, "$.points[1].y: cannot unmarshal string into Go value of type int")
+}
+
+func TestUnmarshalerNestedSource(t *testing.T) {
+	var v struct {
+		Mask  testSource            `json5:"mask"`
+		Price testSource            `json5:"price"`
+		Items []testSource          `json5:"items"`
+		ByKey map[string]testSource `json5:"byKey"`
+	}
+	input := `{
+  mask: 0xFF, // hex
+  price: 1.10,
+  items: [{z: 1, a: 2}, 'x'],
+  byKey: {k: [1, /* two */ 2]},
+}`
+	assert.NoError(t, Unmarshal([]byte(input), &v))
+	assert.Equal(t, testSource("0xFF"), v.Mask)
+	assert.Equal(t, testSource("1.10"), v.Price)
+	assert.Equal(t, []testSource{"{z: 1, a: 2}", "'x'"}, v.Items)
+	assert.Equal(t, map[string]testSource{"k": "[1, /* two */ 2]"}, v.ByKey)
+
+	// The source is that of the value kept for a repeated key
+	var dup struct {
+		A testSource `json5:"a"`
+	}
+	assert.NoError(t, Unmarshal([]byte(`{a: 1, a: 2}`), &dup))
+	assert.Equal(t, testSource("2"), dup.A)
+	assert.NoError(t, UnmarshalWithOptions([]byte(`{a: 1, a: 2}`), &dup, ParseOptions{DuplicateKeys: DuplicateKeysFirstWins}))
+	assert.Equal(t, testSource("1"), dup.A)
+
+	// Reader.Decode passes the source too
+	r := NewReader([]byte(`{price: 1.10}`))
+	var price testSource
+	assert.NoError(t, r.ReadObject(func(key string) error { return r.Decode(&price) }))
+	assert.Equal(t, testSource("1.10"), price)
+}
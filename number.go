@@ -0,0 +1,139 @@
+package json5
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Number is a JSON5 number literal kept exactly as written, such as "0xFF", "1.10" or "12345678901234567890".
+// The parser returns numbers as Number instead of int, int64 or float64 when ParseOptions.UseNumber is set,
+// so no precision or formatting is lost, and Marshal writes a Number back verbatim.
+type Number string
+
+// String returns the number literal
+func (n Number) String() string {
+	return string(n)
+}
+
+// Int64 returns the number as an int64; it fails for fractions, exponents and numbers out of range
+func (n Number) Int64() (int64, error) {
+	i, err := n.BigInt()
+	if err != nil {
+		return 0, err
+	}
+	if !i.IsInt64() {
+		return 0, fmt.Errorf("json5: number %s overflows int64", n)
+	}
+	return i.Int64(), nil
+}
+
+// Uint64 returns the number as a uint64; it fails for negative numbers, fractions, exponents and
+// numbers out of range
+func (n Number) Uint64() (uint64, error) {
+	i, err := n.BigInt()
+	if err != nil {
+		return 0, err
+	}
+	if !i.IsUint64() {
+		return 0, fmt.Errorf("json5: number %s overflows uint64", n)
+	}
+	return i.Uint64(), nil
+}
+
+// Float64 returns the number as a float64, rounding it to the nearest float64
+func (n Number) Float64() (float64, error) {
+	if err := n.check(); err != nil {
+		return 0, err
+	}
+	if n.isHex() {
+		i, err := n.BigInt()
+		if err != nil {
+			return 0, err
+		}
+		if f, _ := new(big.Float).SetInt(i).Float64(); !math.IsInf(f, 0) {
+			return f, nil
+		}
+	} else if f, err := strconv.ParseFloat(string(n), 64); err == nil {
+		return f, nil
+	}
+	return 0, fmt.Errorf("json5: number %s overflows float64", n)
+}
+
+// BigInt returns the number as a *big.Int; it fails for fractions, exponents, Infinity and NaN
+func (n Number) BigInt() (*big.Int, error) {
+	if err := n.check(); err != nil {
+		return nil, err
+	}
+	s, neg := n.unsigned()
+	base := 10
+	if n.isHex() {
+		s, base = s[2:], 16
+	}
+	i, ok := new(big.Int).SetString(s, base)
+	if !ok {
+		return nil, fmt.Errorf("json5: number %s is not an integer", n)
+	}
+	if neg {
+		i.Neg(i)
+	}
+	return i, nil
+}
+
+// BigFloat returns the number as a *big.Float, with enough precision for all its digits; it fails for NaN
+func (n Number) BigFloat() (*big.Float, error) {
+	if err := n.check(); err != nil {
+		return nil, err
+	}
+	s, neg := n.unsigned()
+	switch {
+	case s == "NaN":
+		return nil, fmt.Errorf("json5: number %s cannot be represented as a big.Float", n)
+	case s == "Infinity":
+		return new(big.Float).SetInf(neg), nil
+	case n.isHex():
+		i, err := n.BigInt()
+		if err != nil {
+			return nil, err
+		}
+		return new(big.Float).SetPrec(uint(max(i.BitLen(), 64))).SetInt(i), nil
+	}
+	f, _, err := big.ParseFloat(string(n), 10, uint(max(4*len(n), 64)), big.ToNearestEven)
+	if err != nil {
+		return nil, fmt.Errorf("json5: invalid number %s", n)
+	}
+	return f, nil
+}
+
+// check rejects a Number that is not a valid JSON5 number literal
+func (n Number) check() error {
+	if !isNumberLiteral(string(n)) {
+		return fmt.Errorf("json5: invalid number literal %q", string(n))
+	}
+	return nil
+}
+
+// unsigned returns the number without its sign, and whether it is negative
+func (n Number) unsigned() (string, bool) {
+	s := string(n)
+	if strings.HasPrefix(s, "+") || strings.HasPrefix(s, "-") {
+		return s[1:], s[0] == '-'
+	}
+	return s, false
+}
+
+// isHex reports whether the number is a hexadecimal integer
+func (n Number) isHex() bool {
+	s, _ := n.unsigned()
+	return strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X")
+}
+
+// value converts the number into the int, int64, uint64, *big.Int or float64 the parser returns without UseNumber
+func (n Number) value() (interface{}, error) {
+	if err := n.check(); err != nil {
+		return nil, err
+	}
+	return parseNumber(string(n))
+}
@@ -0,0 +1,97 @@
+package json5
+
+import (
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUseNumber(t *testing.T) {
+	input := `{id: 12345678901234567890123, price: 1.10, mask: 0xFF, neg: -.5, inf: +Infinity}`
+	result, err := UnMarshalWithOptions(input, ParseOptions{UseNumber: true, OrderedObjects: true})
+	assert.NoError(t, err)
+	obj := result.(*Object)
+	id, _ := obj.Get("id")
+	assert.Equal(t, Number("12345678901234567890123"), id)
+	price, _ := obj.Get("price")
+	assert.Equal(t, Number("1.10"), price)
+
+	output, err := Marshal(result)
+	assert.NoError(t, err)
+	assert.Equal(t, "{\nid: 12345678901234567890123,\nprice: 1.10,\nmask: 0xFF,\nneg: -.5,\ninf: +Infinity,\n}", output)
+}
+
+func TestNumberAccessors(t *testing.T) {
+	n := Number("0xFF")
+	i, err := n.Int64()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(255), i)
+	f, err := n.Float64()
+	assert.NoError(t, err)
+	assert.Equal(t, 255.0, f)
+
+	u, err := Number("18446744073709551615").Uint64()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(math.MaxUint64), u)
+	_, err = Number("18446744073709551615").Int64()
+	assert.EqualError(t, err, "json5: number 18446744073709551615 overflows int64")
+	_, err = Number("-1").Uint64()
+	assert.EqualError(t, err, "json5: number -1 overflows uint64")
+	_, err = Number("1.5").Int64()
+	assert.EqualError(t, err, "json5: number 1.5 is not an integer")
+
+	b, err := Number("-0x1FFFFFFFFFFFFFFFFF").BigInt()
+	assert.NoError(t, err)
+	expected, _ := new(big.Int).SetString("-1FFFFFFFFFFFFFFFFF", 16)
+	assert.Equal(t, expected, b)
+
+	bf, err := Number("0.1000000000000000000000000001").BigFloat()
+	assert.NoError(t, err)
+	assert.Equal(t, "0.1000000000000000000000000001", bf.Text('f', 28))
+	bf, err = Number("-Infinity").BigFloat()
+	assert.NoError(t, err)
+	assert.True(t, bf.IsInf() && bf.Signbit())
+	_, err = Number("NaN").BigFloat()
+	assert.EqualError(t, err, "json5: number NaN cannot be represented as a big.Float")
+
+	f, err = Number("5.").Float64()
+	assert.NoError(t, err)
+	assert.Equal(t, 5.0, f)
+	_, err = Number("1e400").Float64()
+	assert.EqualError(t, err, "json5: number 1e400 overflows float64")
+	_, err = Number("inf").Float64()
+	assert.EqualError(t, err, `json5: invalid number literal "inf"`)
+	assert.Equal(t, "1.10", Number("1.10").String())
+}
+
+func TestMarshalInvalidNumber(t *testing.T) {
+	_, err := Marshal([]interface{}{Number("1_000")})
+	assert.EqualError(t, err, `json5: invalid number literal "1_000"`)
+}
+
+func TestUnmarshalNumber(t *testing.T) {
+	var n Number
+	assert.NoError(t, Unmarshal([]byte(`0x10`), &n))
+	assert.Equal(t, Number("16"), n)
+	assert.NoError(t, UnmarshalWithOptions([]byte(`0x10`), &n, ParseOptions{UseNumber: true}))
+	assert.Equal(t, Number("0x10"), n)
+	assert.EqualError(t, Unmarshal([]byte(`'1'`), &n), "$: cannot unmarshal string into Go value of type json5.Number")
+
+	var i int
+	assert.NoError(t, UnmarshalWithOptions([]byte(`0x10`), &i, ParseOptions{UseNumber: true}))
+	assert.Equal(t, 16, i)
+	var items []interface{}
+	assert.NoError(t, UnmarshalWithOptions([]byte(`[1.10]`), &items, ParseOptions{UseNumber: true}))
+	assert.Equal(t, []interface{}{Number("1.10")}, items)
+}
+
+func TestDecoderUseNumber(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(`[99999999999999999999]`))
+	dec.SetOptions(ParseOptions{UseNumber: true})
+	var v interface{}
+	assert.NoError(t, dec.Decode(&v))
+	assert.Equal(t, []interface{}{Number("99999999999999999999")}, v)
+}
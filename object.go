@@ -0,0 +1,64 @@
+package json5
+
+// Object is a JSON5 object that remembers the order in which its keys were added.
+// The parser returns *Object instead of map[string]interface{} when ParseOptions.OrderedObjects is set,
+// and Marshal writes it back in the same order. The zero value is an empty object ready to use.
+type Object struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+// NewObject returns an empty ordered object
+func NewObject() *Object {
+	return &Object{}
+}
+
+// Keys returns the keys of the object in insertion order; the slice must not be modified
+func (o *Object) Keys() []string {
+	return o.keys
+}
+
+// Len returns the number of members of the object
+func (o *Object) Len() int {
+	return len(o.keys)
+}
+
+// Get returns the value stored under key, and whether the key is present
+func (o *Object) Get(key string) (interface{}, bool) {
+	value, ok := o.values[key]
+	return value, ok
+}
+
+// Set stores value under key; a new key is added at the end, an existing one keeps its position
+func (o *Object) Set(key string, value interface{}) {
+	if o.values == nil {
+		o.values = make(map[string]interface{})
+	}
+	if _, ok := o.values[key]; !ok {
+		o.keys = append(o.keys, key)
+	}
+	o.values[key] = value
+}
+
+// Delete removes key from the object, if present
+func (o *Object) Delete(key string) {
+	if _, ok := o.values[key]; !ok {
+		return
+	}
+	delete(o.values, key)
+	for i, k := range o.keys {
+		if k == key {
+			o.keys = append(o.keys[:i], o.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+// Map returns the members of the object as an unordered map
+func (o *Object) Map() map[string]interface{} {
+	result := make(map[string]interface{}, len(o.keys))
+	for key, value := range o.values {
+		result[key] = value
+	}
+	return result
+}
@@ -0,0 +1,70 @@
+package json5
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObject(t *testing.T) {
+	var obj Object
+	obj.Set("b", 1)
+	obj.Set("a", 2)
+	obj.Set("c", 3)
+	obj.Set("b", 4) // keeps its position
+
+	assert.Equal(t, []string{"b", "a", "c"}, obj.Keys())
+	assert.Equal(t, 3, obj.Len())
+	value, ok := obj.Get("b")
+	assert.True(t, ok)
+	assert.Equal(t, 4, value)
+
+	obj.Delete("a")
+	obj.Delete("missing")
+	assert.Equal(t, []string{"b", "c"}, obj.Keys())
+	_, ok = obj.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, map[string]interface{}{"b": 4, "c": 3}, obj.Map())
+}
+
+func TestParseOrderedObjects(t *testing.T) {
+	input := `{
+  zeta: 1,
+  alpha: {second: true, first: false},
+  list: [{y: 1, x: 2}],
+  mid: null,
+}`
+
+	result, err := UnMarshalWithOptions(input, ParseOptions{OrderedObjects: true})
+	assert.NoError(t, err)
+
+	obj := result.(*Object)
+	assert.Equal(t, []string{"zeta", "alpha", "list", "mid"}, obj.Keys())
+	alpha, _ := obj.Get("alpha")
+	assert.Equal(t, []string{"second", "first"}, alpha.(*Object).Keys())
+	list, _ := obj.Get("list")
+	assert.Equal(t, []string{"y", "x"}, list.([]interface{})[0].(*Object).Keys())
+}
+
+func TestOrderedObjectsRoundTrip(t *testing.T) {
+	input := `{
+  zeta: 1,
+  alpha: {
+    second: true,
+    first: "x",
+  },
+  list: [
+    {
+      y: 1,
+      x: 2,
+    }
+  ],
+}`
+
+	result, err := UnMarshalWithOptions(input, ParseOptions{OrderedObjects: true})
+	assert.NoError(t, err)
+
+	output, err := MarshalIndent(result, "  ")
+	assert.NoError(t, err)
+	assert.Equal(t, input, output)
+}
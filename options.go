@@ -0,0 +1,99 @@
+package json5
+
+// Extension is a set of non-standard syntax the parser can accept on top of the JSON5 specification
+type Extension uint
+
+const (
+	ExtBracedUnicodeEscape Extension = 1 << iota // \u{1F600} and \U{0x1F600} escapes
+	ExtLongUnicodeEscape                         // \U0001F600 escapes
+	ExtRawLineBreaks                             // unescaped line breaks inside quoted strings
+	ExtUnquotedStrings                           // identifiers used as string values, not only as keys
+
+	AllExtensions = ExtBracedUnicodeEscape | ExtLongUnicodeEscape | ExtRawLineBreaks | ExtUnquotedStrings
+)
+
+// ParseOptions configures UnMarshalWithOptions and Decoder.SetOptions.
+// The zero value accepts every extension, like UnMarshal.
+type ParseOptions struct {
+	// Strict rejects every extension not listed in Extensions, so only documents
+	// portable to other JSON5 implementations are accepted
+	Strict bool
+	// Extensions lists the extensions still accepted in strict mode
+	Extensions Extension
+	// JSON accepts plain RFC 8259 JSON only, rejecting every JSON5 addition (comments, single quotes,
+	// unquoted keys, trailing commas, hex numbers, ...) with ErrJSON5Only, and every extension
+	JSON bool
+	// OrderedObjects returns objects as *Object, which keeps the keys in source order,
+	// instead of map[string]interface{}
+	OrderedObjects bool
+	// UseNumber returns numbers as Number, which keeps their source text, instead of int, int64 or float64
+	UseNumber bool
+	// RejectBigIntegers reports integers that fit neither an int64 nor a uint64 as ErrIntegerRange
+	// instead of returning them as *big.Int
+	RejectBigIntegers bool
+	// Limits bounds the resources a document may use, for untrusted input. Nil means no limits for
+	// UnMarshalWithOptions and Unmarshal, and DefaultLimits for Decoder and Reader.
+	Limits *Limits
+	// DuplicateKeys chooses what happens when an object repeats a key
+	DuplicateKeys DuplicateKeys
+	// AllowMultipleValues accepts a sequence of top-level values, such as a log of records, which
+	// UnMarshalWithOptions returns as a []interface{} and Unmarshal decodes into a slice. Otherwise
+	// anything but whitespace and comments after the root value is an error. A Decoder always reads
+	// values one by one.
+	AllowMultipleValues bool
+}
+
+// DuplicateKeys is a policy for objects that repeat a key
+type DuplicateKeys int
+
+const (
+	DuplicateKeysLastWins  DuplicateKeys = iota // the last value replaces the earlier ones
+	DuplicateKeysFirstWins                      // the first value is kept, the later ones are parsed and dropped
+	DuplicateKeysError                          // a repeated key fails with a *DuplicateKeyError
+)
+
+// Limits bounds the size of a document, so hostile input cannot exhaust the stack or memory.
+// Exceeding a limit fails with a *LimitError. A zero field means no limit. A Decoder applies them
+// to each top-level value separately.
+type Limits struct {
+	MaxDepth        int   // nesting depth of objects and arrays
+	MaxTokens       int   // number of tokens, comments included
+	MaxStringLength int   // length in bytes of a string, key or identifier as written in the input
+	MaxMembers      int   // number of members of a single object
+	MaxInputBytes   int64 // size of the input in bytes
+}
+
+// withDefaultLimits returns opts with DefaultLimits if it sets no limits, for Decoder and Reader
+func withDefaultLimits(opts ParseOptions) ParseOptions {
+	if opts.Limits == nil {
+		limits := DefaultLimits
+		opts.Limits = &limits
+	}
+	return opts
+}
+
+// DefaultLimits are the limits of a Decoder or Reader whose ParseOptions.Limits is nil
+var DefaultLimits = Limits{
+	MaxDepth:        1000,
+	MaxTokens:       1 << 22,
+	MaxStringLength: 1 << 23,
+	MaxMembers:      1 << 20,
+	MaxInputBytes:   1 << 26,
+}
+
+// allows reports whether the options accept the extension ext
+func (o ParseOptions) allows(ext Extension) bool {
+	if o.JSON {
+		return false
+	}
+	return !o.Strict || o.Extensions&ext != 0
+}
+
+// MarshalOptions configures Marshal, MarshalIndent and Encoder.SetOptions
+type MarshalOptions struct {
+	// SortKeys writes the members of maps sorted lexicographically by key, so the output is deterministic.
+	// *Object values always keep their insertion order.
+	SortKeys bool
+	// KeyLess, if set, sorts the members of maps with a custom comparator instead; it implies SortKeys
+	KeyLess func(a, b string) bool
+}
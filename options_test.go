@@ -0,0 +1,174 @@
+package json5
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStrictModeRejectsExtensions(t *testing.T) {
+	tests := []struct {
+		input     string
+		extension Extension
+		expected  interface{}
+	}{
+		{`"\u{1F600}"`, ExtBracedUnicodeEscape, "\U0001F600"},
+		{`"\U{0x1F600}"`, ExtBracedUnicodeEscape, "\U0001F600"},
+		{`"\U0001F600"`, ExtLongUnicodeEscape, "\U0001F600"},
+		{"'line 1\nline 2'", ExtRawLineBreaks, "line 1\nline 2"},
+		{"'line 1\r\nline 2'", ExtRawLineBreaks, "line 1\r\nline 2"},
+		{"[bare]", ExtUnquotedStrings, []interface{}{"bare"}},
+	}
+
+	for _, test := range tests {
+		// Accepted by default
+		result, err := UnMarshal(test.input)
+		assert.NoError(t, err, test.input)
+		assert.Equal(t, test.expected, result, test.input)
+
+		// Rejected in strict mode
+		_, err = UnMarshalWithOptions(test.input, ParseOptions{Strict: true})
+		assert.ErrorIs(t, err, ErrExtensionNotAllowed, test.input)
+
+		// Accepted in strict mode when allowed explicitly
+		result, err = UnMarshalWithOptions(test.input, ParseOptions{Strict: true, Extensions: test.extension})
+		assert.NoError(t, err, test.input)
+		assert.Equal(t, test.expected, result, test.input)
+
+		// Allowing another extension does not help
+		_, err = UnMarshalWithOptions(test.input, ParseOptions{Strict: true, Extensions: AllExtensions &^ test.extension})
+		assert.ErrorIs(t, err, ErrExtensionNotAllowed, test.input)
+	}
+}
+
+func TestStrictModeAcceptsSpec(t *testing.T) {
+	input := `{
+  // JSON5 features are not extensions
+  unquoted: 'single',
+  escapes: "\x41B😀\
+continued",
+  hex: 0xFF, half: .5, inf: -Infinity,
+  trailing: [1, 2,],
+}`
+
+	result, err := UnMarshalWithOptions(input, ParseOptions{Strict: true})
+	assert.NoError(t, err)
+	assert.Equal(t, "AB\U0001F600continued", result.(map[string]interface{})["escapes"])
+}
+
+func TestDecoderSetOptions(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(`"\u{41}" "\u{42}"`))
+
+	var v interface{}
+	assert.NoError(t, dec.Decode(&v))
+	assert.Equal(t, "A", v)
+
+	dec.SetOptions(ParseOptions{Strict: true})
+	assert.ErrorIs(t, dec.Decode(&v), ErrExtensionNotAllowed)
+}
+
+func TestJSONModeRejectsJSON5Features(t *testing.T) {
+	tests := []struct {
+		input   string
+		feature string
+	}{
+		{`{"a": 1} // note`, "comments"},
+		{`/* note */ {"a": 1}`, "comments"},
+		{`'single'`, "single-quoted strings"},
+		{`{a: 1}`, "unquoted keys"},
+		{`{"a": 1,}`, "trailing commas"},
+		{`[1, 2,]`, "trailing commas"},
+		{`0x1F`, "hexadecimal numbers"},
+		{`+1`, "explicit plus sign"},
+		{`.5`, "leading decimal point"},
+		{`5.`, "trailing decimal point"},
+		{`[5.e3]`, "trailing decimal point"},
+		{`Infinity`, "Infinity and NaN"},
+		{`-NaN`, "Infinity and NaN"},
+		{`"\x41"`, `\x escapes`},
+		{`"\v"`, `\v escapes`},
+		{`"\0"`, `\0 escapes`},
+		{`"\'"`, `\' escapes`},
+		{`"\u{41}"`, `\u{...} escapes`},
+		{"\"a\\\nb\"", "line continuations"},
+		{"\"tab\there\"", "unescaped control characters in strings"},
+		{"\v1", "whitespace character U+000B"},
+		{"[1,\u00a02]", "whitespace character U+00A0"},
+	}
+
+	for _, test := range tests {
+		// Valid JSON5
+		_, err := UnMarshal(test.input)
+		assert.NoError(t, err, test.input)
+
+		_, err = UnMarshalWithOptions(test.input, ParseOptions{JSON: true})
+		assert.ErrorIs(t, err, ErrJSON5Only, test.input)
+		assert.ErrorContains(t, err, "JSON5 feature not allowed in JSON mode: "+test.feature, test.input)
+	}
+}
+
+func TestJSONModeRejectsExtensions(t *testing.T) {
+	for _, input := range []string{`"\u{41}"`, `"\U00000041"`, `[bare]`} {
+		_, err := UnMarshalWithOptions(input, ParseOptions{JSON: true, Extensions: AllExtensions})
+		assert.Error(t, err, input)
+	}
+}
+
+func TestJSONModeAcceptsJSON(t *testing.T) {
+	input := "{\n\t\"name\": \"John \\\"J\\\" Doe\\u0021\",\r\n  \"list\": [1, -2.5e-3, 0, true, false, null, {}, []],\n  \"path\": \"a\\/b\\\\c\\b\\f\\n\\r\\t\"\n}"
+
+	result, err := UnMarshalWithOptions(input, ParseOptions{JSON: true})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{
+		"name": `John "J" Doe!`,
+		"list": []interface{}{1, -2.5e-3, 0, true, false, nil, map[string]interface{}{}, []interface{}{}},
+		"path": "a/b\\c\b\f\n\r\t",
+	}, result)
+}
+
+func TestDuplicateKeys(t *testing.T) {
+	input := "{\n  a: 1,\n  b: [2],\n  a: {c: 3},\n}"
+
+	result, err := UnMarshal(input)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"a": map[string]interface{}{"c": 3}, "b": []interface{}{2}}, result)
+
+	result, err = UnMarshalWithOptions(input, ParseOptions{DuplicateKeys: DuplicateKeysFirstWins})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"a": 1, "b": []interface{}{2}}, result)
+
+	result, err = UnMarshalWithOptions(input, ParseOptions{DuplicateKeys: DuplicateKeysFirstWins, OrderedObjects: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, result.(*Object).Keys())
+
+	_, err = UnMarshalWithOptions(input, ParseOptions{DuplicateKeys: DuplicateKeysError})
+	assert.EqualError(t, err, "4:3: duplicate key 'a', first defined at 2:3")
+	assert.ErrorIs(t, err, ErrDuplicateKey)
+	var dupErr *DuplicateKeyError
+	if assert.True(t, errors.As(err, &dupErr)) {
+		assert.Equal(t, "a", dupErr.Key)
+		assert.Equal(t, 2, dupErr.First.Line)
+		assert.Equal(t, 4, dupErr.Line)
+		assert.Equal(t, "$", dupErr.Path)
+	}
+
+	// Keys are compared after unescaping, and only within the same object
+	_, err = UnMarshalWithOptions(`{x: {a: 1}, y: {a: 2, "a": 3}}`, ParseOptions{DuplicateKeys: DuplicateKeysError})
+	assert.EqualError(t, err, "1:23: duplicate key 'a', first defined at 1:17")
+	assert.Equal(t, `$.y`, err.(*DuplicateKeyError).Path)
+}
+
+func TestDuplicateKeysReader(t *testing.T) {
+	r := NewReader([]byte(`{a: 1, a: 2}`))
+	r.SetOptions(ParseOptions{DuplicateKeys: DuplicateKeysFirstWins})
+	var values []int64
+	err := r.ReadObject(func(key string) error {
+		n, err := r.ReadInt(64)
+		values = append(values, n)
+		return err
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1}, values)
+}
@@ -1,208 +1,476 @@
 package json5
 
 import (
+	"errors"
 	"fmt"
 	"math"
+	"math/big"
 	"strconv"
 	"strings"
+
+	"github.com/shoobyban/json5/internal/jsonpath"
 )
 
 func UnMarshal(json5 string) (interface{}, error) {
-	tokens := Tokenize(json5)
+	return UnMarshalWithOptions(json5, ParseOptions{})
+}
 
-	tokenLen := len(tokens)
+// UnMarshalWithOptions parses a JSON5 string like UnMarshal, with the syntax accepted controlled by opts
+func UnMarshalWithOptions(json5 string, opts ParseOptions) (interface{}, error) {
+	lex := newLexer(strings.NewReader(json5))
+	lex.opts = opts
+	return newParser(lex).parseDocument()
+}
 
-	if tokenLen == 0 {
-		return nil, nil
+// ParseTokens checks a JSON5 string as UnMarshalWithOptions does and returns its tokens, comments and the
+// final TOKEN_EOF included, instead of its value, all from a single pass over the input. It is meant for
+// tools working on the source itself, such as the ast package.
+func ParseTokens(json5 string, opts ParseOptions) ([]Token, error) {
+	lex := newLexer(strings.NewReader(json5))
+	lex.opts = opts
+	p := newParser(lex)
+	p.record = true
+	if _, err := p.parseDocument(); err != nil {
+		return nil, err
 	}
+	return p.tokens, nil
+}
 
-	i := 1 // start from the second token (skip the first one we already checked)
-	if tokens[0].Type == TOKEN_LBRACE {
-		// Parse the object
-		obj, err := parseObject(tokens, &i, tokenLen)
-		if err != nil {
-			return nil, err
+// parseDocument parses the whole input: a single value, or with AllowMultipleValues a list of them
+// located at the paths $[0], $[1]...
+func (p *parser) parseDocument() (interface{}, error) {
+	if p.lex.opts.AllowMultipleValues {
+		values := []interface{}{}
+		for p.peek().Type != TOKEN_EOF {
+			p.path = append(p.path, len(values))
+			value, err := p.parseRoot()
+			if err != nil {
+				return nil, err
+			}
+			p.path = p.path[:len(p.path)-1]
+			values = append(values, value)
 		}
-		return obj, nil
+		return values, nil
 	}
 
-	if tokens[0].Type == TOKEN_LBRACKET {
-		// Parse the array
-		arr, err := parseArray(tokens, &i, tokenLen)
-		if err != nil {
-			return nil, err
-		}
-		return arr, nil
+	value, err := p.parseRoot()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.end(); err != nil {
+		return nil, err
 	}
+	return value, nil
+}
 
-	if tokens[0].Type == TOKEN_STRING {
-		return tokens[0].Value, nil
+// parseRoot parses a top-level value
+func (p *parser) parseRoot() (interface{}, error) {
+	tok := p.peek()
+	switch tok.Type {
+	case TOKEN_LBRACE, TOKEN_LBRACKET, TOKEN_STRING, TOKEN_NUMBER, TOKEN_TRUE, TOKEN_FALSE, TOKEN_NULL:
+		return p.parseValue()
 	}
+	return nil, p.unexpected(tok, []string{"{", "[", "string", "number", "null", "boolean"},
+		"expected '{', '[', string, number, null or boolean but found '%s'", tok.Value)
+}
 
-	if tokens[0].Type == TOKEN_NUMBER {
-		numberStr := tokens[0].Value
-		if num, err := strconv.Atoi(numberStr); err == nil {
-			return num, nil
-		} else if num, err := strconv.ParseFloat(numberStr, 64); err == nil {
-			return num, nil
-		} else {
-			return nil, fmt.Errorf("invalid number: '%s'", numberStr)
+// end checks that nothing but whitespace and comments follows the values parsed; input that cannot be
+// tokenized, such as an unterminated comment, is an error too
+func (p *parser) end() error {
+	tok := p.peek()
+	if tok.Type == TOKEN_EOF {
+		return nil
+	}
+	return p.unexpected(tok, []string{"end of input"}, "expected end of input but found '%s'", tok.Value)
+}
+
+// parser pulls tokens from a lexer and builds Go values from them
+type parser struct {
+	lex   *lexer
+	tok   Token // current token, valid when ok is true
+	ok    bool
+	path  []interface{}   // keys and indices leading to the value being parsed, for error reporting
+	depth int             // number of objects and arrays being parsed, for MaxDepth
+	spans map[string]span // where the values parsed are in the input by path, if recorded
+
+	record bool    // whether to keep the tokens read, for ParseTokens
+	tokens []Token // the tokens read, comments included, if recorded
+}
+
+// newParser returns a parser reading tokens from lex
+func newParser(lex *lexer) *parser {
+	return &parser{lex: lex}
+}
+
+// peek returns the current token, reading it from the lexer if necessary; comments are skipped
+func (p *parser) peek() Token {
+	for !p.ok {
+		p.tok = p.lex.next()
+		if p.record {
+			p.tokens = append(p.tokens, p.tok)
 		}
+		p.ok = p.tok.Type != TOKEN_COMMENT
 	}
+	return p.tok
+}
 
-	if tokens[0].Type == TOKEN_TRUE {
-		return true, nil
+// consume moves past the current token; the next one is only read when needed,
+// so a value at the end of a stream is returned without waiting for more input
+func (p *parser) consume() {
+	p.ok = false
+}
+
+// errorf returns a *SyntaxError located at tok, with the current path
+func (p *parser) errorf(tok Token, err error, expected []string, format string, args ...interface{}) *SyntaxError {
+	return &SyntaxError{
+		Position: tok.Start,
+		Err:      err,
+		Msg:      fmt.Sprintf(format, args...),
+		Expected: expected,
+		Found:    tok.Value,
+		Path:     jsonpath.Format(p.path),
+	}
+}
+
+// tokenError returns the error of a TOKEN_UNKNOWN token, with the current path filled in
+func (p *parser) tokenError(tok Token) error {
+	if err, ok := tok.Err.(*LimitError); ok {
+		withPath := *err
+		withPath.Path = jsonpath.Format(p.path)
+		return &withPath
 	}
+	err, ok := tok.Err.(*SyntaxError)
+	if !ok {
+		return p.errorf(tok, ErrUnexpectedToken, nil, "unexpected token: '%s'", tok.Value)
+	}
+	withPath := *err
+	withPath.Path = jsonpath.Format(p.path)
+	return &withPath
+}
 
-	if tokens[0].Type == TOKEN_FALSE {
-		return false, nil
+// unexpected returns the error for a token that does not fit, reporting the end of input specially
+func (p *parser) unexpected(tok Token, expected []string, format string, args ...interface{}) error {
+	switch {
+	case tok.Type == TOKEN_EOF:
+		return p.errorf(tok, ErrUnexpectedEOF, expected, "unexpected end of input")
+	case tok.Type == TOKEN_UNKNOWN && tok.Err != nil:
+		return p.tokenError(tok)
 	}
+	return p.errorf(tok, ErrUnexpectedToken, expected, format, args...)
+}
 
-	if tokens[0].Type == TOKEN_NULL {
-		return nil, nil
+// limitError returns the error for tok exceeding the limit of the Limits field named limit
+func (p *parser) limitError(tok Token, limit string, max int) error {
+	return &LimitError{Position: tok.Start, Limit: limit, Max: int64(max), Path: jsonpath.Format(p.path)}
+}
+
+// nest enters an object or array whose opening token has just been consumed, checking MaxDepth;
+// the caller must decrement p.depth when it leaves it
+func (p *parser) nest() error {
+	p.depth++
+	if lim := p.lex.opts.Limits; lim != nil && lim.MaxDepth > 0 && p.depth > lim.MaxDepth {
+		return p.limitError(p.tok, "MaxDepth", lim.MaxDepth)
 	}
+	return nil
+}
 
-	return nil, fmt.Errorf("expected '{', '[', number, null or boolean but found '%s'", tokens[0].Value)
+// json5Only returns the error for a JSON5 feature used in JSON mode
+func (p *parser) json5Only(tok Token, feature string) error {
+	return p.errorf(tok, ErrJSON5Only, nil, "%s: %s", ErrJSON5Only, feature)
 }
 
-// parseObject parses the tokens as a JSON5 object and returns a map[string]interface{}
-func parseObject(tokens []Token, i *int, tokenLen int) (map[string]interface{}, error) {
-	result := make(map[string]interface{})
+// parseObject parses the tokens as a JSON5 object and returns a map[string]interface{},
+// or an *Object keeping the key order when ParseOptions.OrderedObjects is set
+func (p *parser) parseObject() (interface{}, error) {
+	var result map[string]interface{}
+	var ordered *Object
+	if p.lex.opts.OrderedObjects {
+		ordered = NewObject()
+	} else {
+		result = make(map[string]interface{})
+	}
+
+	err := p.parseMembers(func(key string) error {
+		value, err := p.parseValue()
+		if err != nil {
+			return err
+		}
+		// Add the key-value pair to the result
+		if ordered != nil {
+			ordered.Set(key, value)
+		} else {
+			result[key] = value
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if ordered != nil {
+		return ordered, nil
+	}
+	return result, nil
+}
 
-	for *i < tokenLen {
+// parseMembers parses the members of an object whose opening brace has been consumed.
+// member is called with each key once its colon has been consumed, and must parse the value.
+func (p *parser) parseMembers(member func(key string) error) error {
+	defer func() { p.depth-- }()
+	if err := p.nest(); err != nil {
+		return err
+	}
+	// Keys seen so far and their positions, when repeated keys are not simply overwritten
+	var seen map[string]Position
+	if p.lex.opts.DuplicateKeys != DuplicateKeysLastWins {
+		seen = make(map[string]Position)
+	}
+	for n := 1; ; n++ {
 		// If we encounter a closing brace, we're done with the object
-		if tokens[*i].Type == TOKEN_RBRACE {
-			*i++ // Move past the closing brace
-			break
+		if p.peek().Type == TOKEN_RBRACE {
+			p.consume() // Move past the closing brace
+			return nil
 		}
 
 		// Parse the key (it should be a string or unquoted identifier)
-		keyToken := tokens[*i]
-		if keyToken.Type != TOKEN_STRING {
-			return nil, fmt.Errorf("expected a string for key but found '%s'", keyToken.Value)
+		keyToken := p.peek()
+		if !isKey(keyToken) {
+			return p.unexpected(keyToken, []string{"string", "}"}, "expected a string for key but found '%s'", keyToken.Value)
+		}
+		if keyToken.Quote == 0 && p.lex.opts.JSON {
+			return p.json5Only(keyToken, "unquoted keys")
+		}
+		if lim := p.lex.opts.Limits; lim != nil && lim.MaxMembers > 0 && n > lim.MaxMembers {
+			return p.limitError(keyToken, "MaxMembers", lim.MaxMembers)
 		}
 		key := keyToken.Value
-		*i++
+		p.consume()
 
 		// Expect a colon after the key
-		if tokens[*i].Type != TOKEN_COLON {
-			return nil, fmt.Errorf("expected ':' after key '%s' but found '%s'", key, tokens[*i].Value)
+		if tok := p.peek(); tok.Type != TOKEN_COLON {
+			return p.unexpected(tok, []string{":"}, "expected ':' after key '%s' but found '%s'", key, tok.Value)
 		}
-		*i++
+		p.consume()
 
-		// Parse the value for the key
-		value, err := parseValue(tokens, i, tokenLen)
-		if err != nil {
-			return nil, err
+		// Parse the value for the key; the value of a repeated key is dropped when the first one wins
+		first, repeated := seen[key]
+		if repeated && p.lex.opts.DuplicateKeys == DuplicateKeysError {
+			return &DuplicateKeyError{Position: keyToken.Start, Key: key, First: first, Path: jsonpath.Format(p.path)}
 		}
-
-		// Add the key-value pair to the result
-		result[key] = value
+		if seen != nil && !repeated {
+			seen[key] = keyToken.Start
+		}
+		p.path = append(p.path, key)
+		if repeated {
+			if _, err := p.parseValue(); err != nil {
+				return err
+			}
+		} else if err := member(key); err != nil {
+			return err
+		}
+		p.path = p.path[:len(p.path)-1]
 
 		// After the value, we should either find a comma or a closing brace
-		if tokens[*i].Type == TOKEN_COMMA {
-			*i++ // Move past the comma
-		} else if tokens[*i].Type == TOKEN_RBRACE {
-			*i++ // Move past the closing brace
-			break
+		if tok := p.peek(); tok.Type == TOKEN_COMMA {
+			p.consume() // Move past the comma
+			if next := p.peek(); next.Type == TOKEN_RBRACE && p.lex.opts.JSON {
+				return p.json5Only(tok, "trailing commas")
+			}
+		} else if tok.Type == TOKEN_RBRACE {
+			p.consume() // Move past the closing brace
+			return nil
 		} else {
-			return nil, fmt.Errorf("expected ',' or '}' but found '%s'", tokens[*i].Value)
+			return p.unexpected(tok, []string{",", "}"}, "expected ',' or '}' but found '%s'", tok.Value)
 		}
 	}
-
-	return result, nil
 }
 
 // parseArray parses the tokens as a JSON5 array and returns a []interface{}
-func parseArray(tokens []Token, i *int, tokenLen int) ([]interface{}, error) {
-	var result []interface{}
+func (p *parser) parseArray() ([]interface{}, error) {
+	result := []interface{}{}
+
+	err := p.parseItems(func(i int) error {
+		value, err := p.parseValue()
+		if err != nil {
+			return err
+		}
+		result = append(result, value)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	for *i < tokenLen {
+	return result, nil
+}
+
+// parseItems parses the items of an array whose opening bracket has been consumed.
+// item is called with the index of each item, and must parse it.
+func (p *parser) parseItems(item func(i int) error) error {
+	defer func() { p.depth-- }()
+	if err := p.nest(); err != nil {
+		return err
+	}
+	for i := 0; ; i++ {
 		// If we encounter a closing bracket, we're done with the array
-		if tokens[*i].Type == TOKEN_RBRACKET {
-			*i++ // Move past the closing bracket
-			break
+		if p.peek().Type == TOKEN_RBRACKET {
+			p.consume() // Move past the closing bracket
+			return nil
 		}
 
 		// Parse the next value
-		value, err := parseValue(tokens, i, tokenLen)
-		if err != nil {
-			return nil, err
+		p.path = append(p.path, i)
+		if err := item(i); err != nil {
+			return err
 		}
-		result = append(result, value)
+		p.path = p.path[:len(p.path)-1]
 
 		// After the value, we should either find a comma or a closing bracket
-		if tokens[*i].Type == TOKEN_COMMA {
-			*i++ // Move past the comma
-		} else if tokens[*i].Type == TOKEN_RBRACKET {
-			*i++ // Move past the closing bracket
-			break
+		if tok := p.peek(); tok.Type == TOKEN_COMMA {
+			p.consume() // Move past the comma
+			if next := p.peek(); next.Type == TOKEN_RBRACKET && p.lex.opts.JSON {
+				return p.json5Only(tok, "trailing commas")
+			}
+		} else if tok.Type == TOKEN_RBRACKET {
+			p.consume() // Move past the closing bracket
+			return nil
 		} else {
-			return nil, fmt.Errorf("expected ',' or ']' but found '%s'", tokens[*i].Value)
+			return p.unexpected(tok, []string{",", "]"}, "expected ',' or ']' but found '%s'", tok.Value)
 		}
 	}
-
-	return result, nil
 }
 
 // parseValue parses a value (string, number, boolean, null, object, or array)
-func parseValue(tokens []Token, i *int, tokenLen int) (interface{}, error) {
-	if *i >= tokenLen {
-		return nil, fmt.Errorf("unexpected end of input")
+func (p *parser) parseValue() (interface{}, error) {
+	if p.spans == nil {
+		return p.parseToken()
+	}
+	start := p.peek().Start.Offset
+	value, err := p.parseToken()
+	if err != nil {
+		return nil, err
 	}
+	// The value kept for a repeated key is the first one or the last one, depending on the policy
+	key := jsonpath.Format(p.path)
+	if _, ok := p.spans[key]; !ok || p.lex.opts.DuplicateKeys != DuplicateKeysFirstWins {
+		p.spans[key] = span{start: start, end: p.tok.End.Offset}
+	}
+	return value, nil
+}
 
-	switch tokens[*i].Type {
+// parseToken parses the value starting with the next token
+func (p *parser) parseToken() (interface{}, error) {
+	tok := p.peek()
+	switch tok.Type {
 	case TOKEN_STRING:
-		value := tokens[*i].Value
-		*i++
-		return value, nil
+		if tok.Quote == 0 && !p.lex.opts.allows(ExtUnquotedStrings) {
+			return nil, p.errorf(tok, ErrExtensionNotAllowed, []string{"value"},
+				"non-standard extension not allowed: unquoted string '%s'", tok.Value)
+		}
+		p.consume()
+		return tok.Value, nil
 	case TOKEN_NUMBER:
-		numberStr := tokens[*i].Value
-		*i++
-		// Check if the number is hexadecimal
-		if strings.HasPrefix(numberStr, "0x") || strings.HasPrefix(numberStr, "0X") {
-			// Parse the hexadecimal number
-			num, err := strconv.ParseInt(numberStr, 0, 64)
-			if err != nil {
-				return nil, fmt.Errorf("invalid hexadecimal number: '%s'", numberStr)
-			}
-			if abs(num) < math.MaxInt {
-				return int(num), nil
-			}
-			return num, nil
-		} else {
-			// Parse as a regular decimal number (int or float)
-			if num, err := strconv.Atoi(numberStr); err == nil {
-				return num, nil
-			} else if num, err := strconv.ParseFloat(numberStr, 64); err == nil {
-				return num, nil
-			} else {
-				return nil, fmt.Errorf("invalid number: '%s'", numberStr)
-			}
+		p.consume()
+		if p.lex.opts.UseNumber {
+			// The lexer has already checked the literal
+			return Number(tok.Value), nil
+		}
+		num, err := parseNumber(tok.Value)
+		if err != nil {
+			return nil, p.errorf(tok, ErrInvalidNumber, nil, "%s", err)
 		}
+		if _, ok := num.(*big.Int); ok && p.lex.opts.RejectBigIntegers {
+			return nil, p.errorf(tok, ErrIntegerRange, nil, "integer out of range: '%s'", tok.Value)
+		}
+		return num, nil
 	case TOKEN_TRUE:
-		*i++
+		p.consume()
 		return true, nil
 	case TOKEN_FALSE:
-		*i++
+		p.consume()
 		return false, nil
 	case TOKEN_NULL:
-		*i++
+		p.consume()
 		return nil, nil // Return nil when the value is the literal `null`
 	case TOKEN_LBRACE:
-		*i++ // Move past the opening brace
-		return parseObject(tokens, i, tokenLen)
+		p.consume() // Move past the opening brace
+		return p.parseObject()
 	case TOKEN_LBRACKET:
-		*i++ // Move past the opening bracket
-		return parseArray(tokens, i, tokenLen)
+		p.consume() // Move past the opening bracket
+		return p.parseArray()
 	default:
-		return nil, fmt.Errorf("unexpected token: '%s'", tokens[*i].Value)
+		return nil, p.unexpected(tok, []string{"value"}, "unexpected token: '%s'", tok.Value)
+	}
+}
+
+// parseNumber converts a number token into a Go number: integers become an int when they fit, then an int64,
+// a uint64 or a *big.Int; other numbers, Infinity and NaN become float64
+func parseNumber(numberStr string) (interface{}, error) {
+	unsigned := strings.TrimLeft(numberStr, "+-")
+	switch unsigned {
+	case "Infinity":
+		if strings.HasPrefix(numberStr, "-") {
+			return math.Inf(-1), nil
+		}
+		return math.Inf(1), nil
+	case "NaN":
+		return math.NaN(), nil
+	}
+
+	if strings.HasPrefix(unsigned, "0x") || strings.HasPrefix(unsigned, "0X") {
+		return parseInteger(unsigned[2:], 16, strings.HasPrefix(numberStr, "-"))
+	}
+	if !strings.ContainsAny(unsigned, ".eE") {
+		return parseInteger(unsigned, 10, strings.HasPrefix(numberStr, "-"))
+	}
+	// Literals beyond the float64 range, such as 1e400, evaluate to ±Infinity as in JavaScript
+	if num, err := strconv.ParseFloat(numberStr, 64); err == nil || errors.Is(err, strconv.ErrRange) {
+		return num, nil
+	}
+	return nil, fmt.Errorf("invalid number: '%s'", numberStr)
+}
+
+// parseInteger converts the digits of an integer in the given base into the smallest of int, int64, uint64
+// and *big.Int that holds it
+func parseInteger(digits string, base int, negative bool) (interface{}, error) {
+	u, err := strconv.ParseUint(digits, base, 64)
+	if errors.Is(err, strconv.ErrRange) || (negative && u > 1<<63) {
+		b, ok := new(big.Int).SetString(digits, base)
+		if !ok {
+			return nil, fmt.Errorf("invalid number: '%s'", digits)
+		}
+		if negative {
+			b.Neg(b)
+		}
+		return b, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid number: '%s'", digits)
+	}
+
+	if negative {
+		// Two's complement negation, exact for every u up to 1<<63, the magnitude of math.MinInt64
+		n := int64(-u)
+		if n >= math.MinInt {
+			return int(n), nil
+		}
+		return n, nil
+	}
+	switch {
+	case u <= math.MaxInt:
+		return int(u), nil
+	case u <= math.MaxInt64:
+		return int64(u), nil
 	}
+	return u, nil
 }
 
-func abs(x int64) int64 {
-	if x < 0 {
-		return -x
+// isKey checks if tok can be used as an object key: a string, or an identifier the tokenizer read as a
+// literal, whose value is its text
+func isKey(tok Token) bool {
+	switch tok.Type {
+	case TOKEN_STRING, TOKEN_TRUE, TOKEN_FALSE, TOKEN_NULL:
+		return true
 	}
-	return x
+	return tok.Type == TOKEN_NUMBER && (tok.Value == "Infinity" || tok.Value == "NaN")
 }
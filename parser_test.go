@@ -1,6 +1,9 @@
 package json5
 
 import (
+	"math"
+	"math/big"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -103,3 +106,326 @@ func BenchmarkJSON(b *testing.B) {
 		UnMarshal(input)
 	}
 }
+
+func TestParseErrorPositions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"{\n  a: 1\n  b: 2\n}", "3:3: expected ',' or '}' but found 'b'"},
+		{"{\n  a 1\n}", "2:5: expected ':' after key 'a' but found '1'"},
+		{"[1, 2\n  3]", "2:3: expected ',' or ']' but found '3'"},
+		{"{a: [1,\n", "2:1: unexpected end of input"},
+		{"  ]", "1:3: expected '{', '[', string, number, null or boolean but found ']'"},
+		{"{a: ]}", "1:5: unexpected token: ']'"},
+	}
+
+	for _, test := range tests {
+		_, err := UnMarshal(test.input)
+		assert.EqualError(t, err, test.expected, test.input)
+	}
+}
+
+func TestParseComments(t *testing.T) {
+	obj := map[string]interface{}{"a": 1, "b": 2}
+	tests := []struct {
+		name     string
+		input    string
+		expected interface{}
+	}{
+		{"header before the root", "// config\n/* v1 */ {a: 1, b: 2}", obj},
+		{"after the root", "{a: 1, b: 2} // end\n/* eof */", obj},
+		{"only comments around a scalar", "/* a */ 42 // b", 42},
+		{"after a comma", "{ a: 1, // note\n b: 2 }", obj},
+		{"before a comma", "{a: 1 /* note */, b: 2}", obj},
+		{"before a key", "{/* first */ a: 1, // second\n b: 2}", obj},
+		{"between key and colon", "{a /* k */: 1, 'b' // k\n : 2}", obj},
+		{"between colon and value", "{a: /* v */ 1, b: // v\n 2}", obj},
+		{"before the closing brace", "{a: 1, b: 2 // last\n}", obj},
+		{"after a trailing comma", "{a: 1, b: 2, /* trailing */}", obj},
+		{"inside arrays", "[ /* 0 */ 1, // 1\n 2 /* 2 */, ]", []interface{}{1, 2}},
+		{"empty object", "{ // nothing\n}", map[string]interface{}{}},
+		{"empty array", "[/* nothing */]", []interface{}{}},
+		{"nested", "{a: [/* x */ {/* y */ b: /* z */ null}]}", map[string]interface{}{"a": []interface{}{map[string]interface{}{"b": nil}}}},
+		{"comment markers in strings", `{a: "// not a comment", b: '/* nor this */'}`, map[string]interface{}{"a": "// not a comment", "b": "/* nor this */"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := UnMarshal(test.input)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, result)
+
+			var v interface{}
+			assert.NoError(t, Unmarshal([]byte(test.input), &v))
+		})
+	}
+
+	_, err := UnMarshal("// only a comment")
+	assert.EqualError(t, err, "1:18: unexpected end of input")
+	_, err = UnMarshal("{a: 1 /* unterminated }")
+	assert.ErrorIs(t, err, ErrUnterminatedComment)
+}
+
+func TestParseTrailingData(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"{a: 1} garbage", "1:8: expected end of input but found 'garbage'"},
+		{"[1, 2]]", "1:7: expected end of input but found ']'"},
+		{"1 2", "1:3: expected end of input but found '2'"},
+		{`"a" "b"`, "1:5: expected end of input but found 'b'"},
+		{"null // comment\ntrue", "2:1: expected end of input but found 'true'"},
+		{"{} {}", "1:4: expected end of input but found '{'"},
+		{"[] /* unterminated", "1:4: unterminated comment"},
+	}
+
+	for _, test := range tests {
+		result, err := UnMarshal(test.input)
+		assert.EqualError(t, err, test.expected, test.input)
+		assert.Nil(t, result, test.input)
+
+		var v interface{}
+		assert.EqualError(t, Unmarshal([]byte(test.input), &v), test.expected, test.input)
+	}
+
+	var syntaxErr *SyntaxError
+	_, err := UnMarshal("{a: 1}\n  ]")
+	assert.ErrorAs(t, err, &syntaxErr)
+	assert.ErrorIs(t, err, ErrUnexpectedToken)
+	assert.Equal(t, Position{Line: 2, Column: 3, Offset: 9}, syntaxErr.Position)
+	assert.Equal(t, []string{"end of input"}, syntaxErr.Expected)
+}
+
+func TestParseMultipleValues(t *testing.T) {
+	opts := ParseOptions{AllowMultipleValues: true}
+
+	result, err := UnMarshalWithOptions("{a: 1}\n// next\n{a: 2} [3] 'four' null", opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"a": 1}, map[string]interface{}{"a": 2}, []interface{}{3}, "four", nil,
+	}, result)
+
+	result, err = UnMarshalWithOptions(" // nothing\n", opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{}, result)
+
+	_, err = UnMarshalWithOptions("{a: 1} ]", opts)
+	assert.EqualError(t, err, "1:8: expected '{', '[', string, number, null or boolean but found ']'")
+	var syntaxErr *SyntaxError
+	assert.ErrorAs(t, err, &syntaxErr)
+	assert.Equal(t, []string{"{", "[", "string", "number", "null", "boolean"}, syntaxErr.Expected)
+}
+
+func TestParseTruncatedDocument(t *testing.T) {
+	input := `{
+  name: "John \"J\" Doe",
+  'single': 'it\'s',
+  esc: "A\t\U0001F600",
+  list: [1, -2.5, 0x1F, true, false, null],
+  nested: {a: {b: []}},
+}`
+
+	_, err := UnMarshal(input)
+	assert.NoError(t, err)
+
+	// Every proper prefix of the document is incomplete and must be rejected, never partially accepted
+	for i := 1; i < len(input); i++ {
+		result, err := UnMarshal(input[:i])
+		assert.Error(t, err, input[:i])
+		assert.Nil(t, result, input[:i])
+	}
+}
+
+func TestParseNumbers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`0`, 0},
+		{`-7`, -7},
+		{`+1`, 1},
+		{`.5`, 0.5},
+		{`-.5`, -0.5},
+		{`5.`, 5.0},
+		{`+5.e2`, 500.0},
+		{`1e+10`, 1e10},
+		{`1E-2`, 0.01},
+		{`0xFF`, 255},
+		{`-0x1f`, -31},
+		{`+0X10`, 16},
+		{`Infinity`, math.Inf(1)},
+		{`+Infinity`, math.Inf(1)},
+		{`-Infinity`, math.Inf(-1)},
+		{`1e400`, math.Inf(1)},
+		{`-1.5E+999`, math.Inf(-1)},
+		{`1e-400`, 0.0},
+	}
+
+	for _, test := range tests {
+		result, err := UnMarshal(test.input)
+		assert.NoError(t, err, test.input)
+		assert.Equal(t, test.expected, result, test.input)
+	}
+
+	for _, input := range []string{`NaN`, `-NaN`, `+NaN`} {
+		result, err := UnMarshal(input)
+		assert.NoError(t, err, input)
+		assert.True(t, math.IsNaN(result.(float64)), input)
+	}
+
+	result, err := UnMarshal(`{Infinity: -Infinity, NaN: 1, list: [.5, 5., +1]}`)
+	assert.NoError(t, err)
+	obj := result.(map[string]interface{})
+	assert.Equal(t, math.Inf(-1), obj["Infinity"])
+	assert.Equal(t, 1, obj["NaN"])
+	assert.Equal(t, []interface{}{0.5, 5.0, 1}, obj["list"])
+}
+
+func TestParseIntegerRange(t *testing.T) {
+	if strconv.IntSize != 64 {
+		t.Skip("boundaries assume a 64-bit int")
+	}
+	bigInt := func(s string) *big.Int {
+		b, _ := new(big.Int).SetString(s, 0)
+		return b
+	}
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`-0`, 0},
+		{`9223372036854775807`, math.MaxInt},
+		{`-9223372036854775808`, math.MinInt},
+		{`9223372036854775808`, uint64(1 << 63)},
+		{`18446744073709551615`, uint64(math.MaxUint64)},
+		{`18446744073709551616`, bigInt("18446744073709551616")},
+		{`-9223372036854775809`, bigInt("-9223372036854775809")},
+		{`123456789012345678901234567890`, bigInt("123456789012345678901234567890")},
+		{`0x7FFFFFFFFFFFFFFF`, math.MaxInt},
+		{`-0x8000000000000000`, math.MinInt},
+		{`0x8000000000000000`, uint64(1 << 63)},
+		{`+0xFFFFFFFFFFFFFFFF`, uint64(math.MaxUint64)},
+		{`0x10000000000000000`, bigInt("0x10000000000000000")},
+		{`-0xFFFFFFFFFFFFFFFF`, bigInt("-0xFFFFFFFFFFFFFFFF")},
+		{`-0x8000000000000001`, bigInt("-0x8000000000000001")},
+		{`1e20`, 1e20},
+		{`18446744073709551616.0`, 18446744073709551616.0},
+	}
+
+	for _, test := range tests {
+		result, err := UnMarshal(test.input)
+		assert.NoError(t, err, test.input)
+		assert.Equal(t, test.expected, result, test.input)
+
+		out, err := Marshal(result)
+		assert.NoError(t, err, test.input)
+		back, err := UnMarshal(out)
+		assert.NoError(t, err, test.input)
+		assert.Equal(t, result, back, test.input)
+	}
+}
+
+func TestParseRejectBigIntegers(t *testing.T) {
+	opts := ParseOptions{RejectBigIntegers: true}
+	result, err := UnMarshalWithOptions(`[18446744073709551615, -9223372036854775808]`, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{uint64(math.MaxUint64), int64(math.MinInt64)}, normalizeInts(result))
+
+	for _, input := range []string{`18446744073709551616`, `-9223372036854775809`, `0x10000000000000000`} {
+		_, err := UnMarshalWithOptions(input, opts)
+		assert.ErrorIs(t, err, ErrIntegerRange, input)
+		assert.EqualError(t, err, "1:1: integer out of range: '"+input+"'")
+	}
+}
+
+// normalizeInts converts every int in v to int64, so tests do not depend on the size of int
+func normalizeInts(v interface{}) interface{} {
+	switch v := v.(type) {
+	case int:
+		return int64(v)
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeInts(item)
+		}
+	}
+	return v
+}
+
+func TestParseInvalidNumbers(t *testing.T) {
+	for _, input := range []string{`01`, `-`, `+`, `.`, `1e`, `1e+`, `0x`, `1.2.3`, `1abc`, `-Infinit`, `+foo`, `0x1G`, `..5`, `1e5.5`} {
+		_, err := UnMarshal(input)
+		assert.ErrorIs(t, err, ErrInvalidNumber, input)
+	}
+}
+
+func TestParseStringEscapes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"\x41\x7e"`, "A~"},
+		{`"a\0b"`, "a\x00b"},
+		{`"\b\f\v\n\r\t"`, "\b\f\v\n\r\t"},
+		{`'\'\"\\\/'`, `'"\/`},
+		{`"\a\c\é"`, "ac\u00e9"},
+		{"\"line 1 \\\nline 2\"", "line 1 line 2"},
+		{"\"line 1 \\\r\nline 2\"", "line 1 line 2"},
+		{"\"line 1 \\\rline 2\"", "line 1 line 2"},
+		{"\"line 1 \\\u2028line 2 \\\u2029line 3\"", "line 1 line 2 line 3"},
+		{`"\u0041\u00e9"`, "A\u00e9"},
+		{`"\uD83D\uDE00"`, "\U0001F600"},
+		{`"\ud83d\ude00!"`, "\U0001F600!"},
+		{`"\uD83D"`, "\uFFFD"},
+		{`"\uDE00\uD83D"`, "\uFFFD\uFFFD"},
+		{`"\U0001F600"`, "\U0001F600"},
+		{`"\u{1F600}"`, "\U0001F600"},
+	}
+
+	for _, test := range tests {
+		result, err := UnMarshal(test.input)
+		assert.NoError(t, err, test.input)
+		assert.Equal(t, test.expected, result, test.input)
+	}
+}
+
+func TestParseInvalidStringEscapes(t *testing.T) {
+	for _, input := range []string{`"\x4"`, `"\xZZ"`, `"\01"`, `"\1"`, `"\9"`, `"\u12"`, `"\u12G4"`} {
+		_, err := UnMarshal(input)
+		assert.ErrorIs(t, err, ErrInvalidEscape, input)
+	}
+}
+
+func TestParseKeywordKeys(t *testing.T) {
+	expected := map[string]interface{}{"true": 1, "false": 2, "null": 3}
+	result, err := UnMarshal(`{true: 1, false: 2, null: 3}`)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+
+	// Marshal writes them unquoted, as identifiers
+	out, err := Marshal(expected)
+	assert.NoError(t, err)
+	back, err := UnMarshal(out)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, back)
+
+	_, err = UnMarshalWithOptions(`{null: 1}`, ParseOptions{JSON: true})
+	assert.ErrorIs(t, err, ErrJSON5Only)
+}
+
+func TestParseTokens(t *testing.T) {
+	tokens, err := ParseTokens("{a: /* c */ 1} // end\n", ParseOptions{})
+	assert.NoError(t, err)
+	var types []TokenType
+	for _, tok := range tokens {
+		types = append(types, tok.Type)
+	}
+	assert.Equal(t, []TokenType{
+		TOKEN_LBRACE, TOKEN_STRING, TOKEN_COLON, TOKEN_COMMENT, TOKEN_NUMBER, TOKEN_RBRACE, TOKEN_COMMENT, TOKEN_EOF,
+	}, types)
+	assert.Equal(t, 22, tokens[len(tokens)-1].Start.Offset)
+
+	_, err = ParseTokens("{a: 1} 2", ParseOptions{})
+	assert.EqualError(t, err, "1:8: expected end of input but found '2'")
+}
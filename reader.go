@@ -0,0 +1,223 @@
+package json5
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Reader decodes a JSON5 document value by value, straight from the token stream, for code that fills
+// its own types without building maps and slices of interface{} first, such as the UnmarshalJSON5
+// methods generated by cmd/json5gen. Errors carry the JSON path of the value being read.
+type Reader struct {
+	p    *parser
+	data []byte
+}
+
+// NewReader returns a Reader decoding data, subject to DefaultLimits unless SetOptions sets other limits
+func NewReader(data []byte) *Reader {
+	lex := newLexer(bytes.NewReader(data))
+	lex.opts = withDefaultLimits(ParseOptions{})
+	return &Reader{p: newParser(lex), data: data}
+}
+
+// SetOptions controls the syntax accepted for the values read from now on.
+// If opts.Limits is nil, DefaultLimits apply.
+func (r *Reader) SetOptions(opts ParseOptions) {
+	r.p.lex.opts = withDefaultLimits(opts)
+}
+
+// readerFrom is implemented by types that read themselves from a Reader, such as those generated by
+// cmd/json5gen; Unmarshal uses it so their errors carry the path of the whole document
+type readerFrom interface {
+	ReadJSON5(r *Reader) error
+}
+
+// readDocument reads a whole document into v straight from data, for Unmarshal
+func readDocument(data []byte, v readerFrom, opts ParseOptions) error {
+	r := NewReader(data)
+	// Like UnMarshal, without limits unless asked
+	r.p.lex.opts = opts
+	if err := v.ReadJSON5(r); err != nil {
+		return err
+	}
+	return r.End()
+}
+
+// readFrom passes the source of a decoded value to v through a Reader starting at path
+func readFrom(value interface{}, v readerFrom, path []interface{}, src *source) error {
+	data, err := src.text(value, path)
+	if err != nil {
+		return err
+	}
+	var opts ParseOptions
+	if src != nil {
+		opts = src.opts
+	}
+	// The value has already been parsed within the limits of the caller
+	opts.Limits = &Limits{}
+	r := NewReader([]byte(data))
+	r.SetOptions(opts)
+	r.p.path = append([]interface{}(nil), path...)
+	if err := v.ReadJSON5(r); err != nil {
+		return err
+	}
+	return r.End()
+}
+
+// ReadObject reads an object, calling member with each of its keys. member must read the value,
+// with one of the Read methods or Skip.
+func (r *Reader) ReadObject(member func(key string) error) error {
+	if r.p.peek().Type != TOKEN_LBRACE {
+		return r.typeError("object")
+	}
+	r.p.consume()
+	return r.p.parseMembers(member)
+}
+
+// ReadArray reads an array, calling item with the index of each of its items. item must read the value,
+// with one of the Read methods or Skip.
+func (r *Reader) ReadArray(item func(i int) error) error {
+	if r.p.peek().Type != TOKEN_LBRACKET {
+		return r.typeError("array")
+	}
+	r.p.consume()
+	return r.p.parseItems(item)
+}
+
+// MatchKey returns the one of keys that the object key selects, as Unmarshal matches struct fields:
+// exactly, or else ignoring case. It returns key itself if none matches.
+func (r *Reader) MatchKey(key string, keys ...string) string {
+	for _, k := range keys {
+		if k == key {
+			return k
+		}
+	}
+	for _, k := range keys {
+		if strings.EqualFold(k, key) {
+			return k
+		}
+	}
+	return key
+}
+
+// ReadNull reads a null and returns true if it is the next value; otherwise it reads nothing
+func (r *Reader) ReadNull() bool {
+	if r.p.peek().Type == TOKEN_NULL {
+		r.p.consume()
+		return true
+	}
+	return false
+}
+
+// ReadString reads a string
+func (r *Reader) ReadString() (string, error) {
+	value, err := r.p.parseValue()
+	if err != nil {
+		return "", err
+	}
+	return asString(value, r.p.path)
+}
+
+// ReadBool reads a boolean
+func (r *Reader) ReadBool() (bool, error) {
+	value, err := r.p.parseValue()
+	if err != nil {
+		return false, err
+	}
+	return asBool(value, r.p.path)
+}
+
+// ReadInt reads a number that fits a signed integer of bitSize bits, or an int if bitSize is 0
+func (r *Reader) ReadInt(bitSize int) (int64, error) {
+	value, err := r.p.parseValue()
+	if err != nil {
+		return 0, err
+	}
+	typeName := sizedTypeName("int", bitSize)
+	if bitSize == 0 {
+		bitSize = strconv.IntSize
+	}
+	n, ok := toInt64(value)
+	if !ok || n < -1<<(bitSize-1) || n > 1<<(bitSize-1)-1 {
+		return 0, typeMismatch(value, r.p.path, typeName)
+	}
+	return n, nil
+}
+
+// ReadUint reads a non-negative number that fits an unsigned integer of bitSize bits, or a uint if bitSize is 0
+func (r *Reader) ReadUint(bitSize int) (uint64, error) {
+	value, err := r.p.parseValue()
+	if err != nil {
+		return 0, err
+	}
+	typeName := sizedTypeName("uint", bitSize)
+	if bitSize == 0 {
+		bitSize = strconv.IntSize
+	}
+	n, ok := toUint64(value)
+	if !ok || (bitSize < 64 && n > 1<<bitSize-1) {
+		return 0, typeMismatch(value, r.p.path, typeName)
+	}
+	return n, nil
+}
+
+// ReadFloat reads a number that fits a float of bitSize bits, 32 or 64
+func (r *Reader) ReadFloat(bitSize int) (float64, error) {
+	value, err := r.p.parseValue()
+	if err != nil {
+		return 0, err
+	}
+	f, ok := toFloat64(value)
+	if !ok || (bitSize == 32 && math.Abs(f) > math.MaxFloat32 && !math.IsInf(f, 0)) {
+		return 0, typeMismatch(value, r.p.path, sizedTypeName("float", bitSize))
+	}
+	return f, nil
+}
+
+// ReadValue reads any value, returned as UnMarshal would
+func (r *Reader) ReadValue() (interface{}, error) {
+	return r.p.parseValue()
+}
+
+// Decode reads a value and stores it in the value pointed to by v, as Unmarshal does
+func (r *Reader) Decode(v interface{}) error {
+	start := r.p.peek().Start.Offset
+	value, err := r.p.parseValue()
+	if err != nil {
+		return err
+	}
+	path := append([]interface{}(nil), r.p.path...)
+	src := &source{data: string(r.data[start:r.p.tok.End.Offset]), path: path, opts: r.p.lex.opts}
+	return unmarshalValue(value, v, r.p.path, src)
+}
+
+// Skip reads a value and discards it
+func (r *Reader) Skip() error {
+	_, err := r.p.parseValue()
+	return err
+}
+
+// End checks that nothing but whitespace and comments is left after the values read
+func (r *Reader) End() error {
+	return r.p.end()
+}
+
+// typeError reports a value other than the object or array expected by ReadObject and ReadArray,
+// reading it to describe it
+func (r *Reader) typeError(expected string) error {
+	value, err := r.p.parseValue()
+	if err != nil {
+		return err
+	}
+	return typeMismatch(value, r.p.path, expected)
+}
+
+// sizedTypeName returns the Go name of an integer type of bitSize bits, such as int32, or name if bitSize is 0
+func sizedTypeName(name string, bitSize int) string {
+	if bitSize == 0 {
+		return name
+	}
+	return name + strconv.Itoa(bitSize)
+}
@@ -0,0 +1,210 @@
+package json5
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// ReadJSON5 reads a point written by WriteJSON5, like the types generated by cmd/json5gen
+func (p *point) ReadJSON5(r *Reader) error {
+	return r.ReadObject(func(key string) error {
+		var n int64
+		var err error
+		switch key {
+		case "x":
+			n, err = r.ReadInt(0)
+			p.X = int(n)
+		case "y":
+			n, err = r.ReadInt(0)
+			p.Y = int(n)
+		default:
+			err = r.Skip()
+		}
+		return err
+	})
+}
+
+func (p *point) UnmarshalJSON5(data []byte) error {
+	r := NewReader(data)
+	if err := p.ReadJSON5(r); err != nil {
+		return err
+	}
+	return r.End()
+}
+
+func TestReader(t *testing.T) {
+	r := NewReader([]byte(`{
+		// comment
+		name: 'svc',
+		port: 0x1F90,
+		ratio: .5,
+		on: true,
+		tags: ['a', null],
+		extra: {a: [1]},
+		unknown: [1, {b: 2}],
+	}`))
+	var name, tags []string
+	var port uint64
+	var ratio float64
+	var on bool
+	var extra interface{}
+	err := r.ReadObject(func(key string) error {
+		var err error
+		switch key {
+		case "name":
+			var s string
+			s, err = r.ReadString()
+			name = append(name, s)
+		case "port":
+			port, err = r.ReadUint(16)
+		case "ratio":
+			ratio, err = r.ReadFloat(64)
+		case "on":
+			on, err = r.ReadBool()
+		case "tags":
+			err = r.ReadArray(func(i int) error {
+				if r.ReadNull() {
+					tags = append(tags, "<null>")
+					return nil
+				}
+				s, err := r.ReadString()
+				tags = append(tags, s)
+				return err
+			})
+		case "extra":
+			extra, err = r.ReadValue()
+		default:
+			err = r.Skip()
+		}
+		return err
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, r.End())
+	assert.Equal(t, []string{"svc"}, name)
+	assert.Equal(t, uint64(8080), port)
+	assert.Equal(t, 0.5, ratio)
+	assert.True(t, on)
+	assert.Equal(t, []string{"a", "<null>"}, tags)
+	assert.Equal(t, map[string]interface{}{"a": []interface{}{1}}, extra)
+}
+
+func TestReaderDecode(t *testing.T) {
+	r := NewReader([]byte(`[{a: 'x'}, {a: 1}]`))
+	var items []map[string]string
+	err := r.ReadArray(func(i int) error {
+		var item map[string]string
+		if err := r.Decode(&item); err != nil {
+			return err
+		}
+		items = append(items, item)
+		return nil
+	})
+	assert.EqualError(t, err, "$[1].a: cannot unmarshal number 1 into Go value of type string")
+	assert.Equal(t, []map[string]string{{"a": "x"}}, items)
+}
+
+func TestReaderErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		read     func(r *Reader) error
+		expected string
+	}{
+		{`[1]`, func(r *Reader) error {
+			return r.ReadObject(func(string) error { return nil })
+		}, "$: expected object but found array"},
+		{`{a: 'x'}`, func(r *Reader) error {
+			return r.ReadArray(func(int) error { return nil })
+		}, "$: expected array but found object"},
+		{`{a: 'x'}`, func(r *Reader) error {
+			return r.ReadObject(func(string) error {
+				_, err := r.ReadInt(0)
+				return err
+			})
+		}, "$.a: cannot unmarshal string into Go value of type int"},
+		{`[128]`, func(r *Reader) error {
+			return r.ReadArray(func(int) error {
+				_, err := r.ReadInt(8)
+				return err
+			})
+		}, "$[0]: cannot unmarshal number 128 into Go value of type int8"},
+		{`[-1]`, func(r *Reader) error {
+			return r.ReadArray(func(int) error {
+				_, err := r.ReadUint(0)
+				return err
+			})
+		}, "$[0]: cannot unmarshal number -1 into Go value of type uint"},
+		{`[1.5]`, func(r *Reader) error {
+			return r.ReadArray(func(int) error {
+				_, err := r.ReadInt(64)
+				return err
+			})
+		}, "$[0]: cannot unmarshal number 1.5 into Go value of type int64"},
+		{`[1e300]`, func(r *Reader) error {
+			return r.ReadArray(func(int) error {
+				_, err := r.ReadFloat(32)
+				return err
+			})
+		}, "$[0]: cannot unmarshal number 1e+300 into Go value of type float32"},
+		{`{a: 1} 2`, func(r *Reader) error {
+			if err := r.Skip(); err != nil {
+				return err
+			}
+			return r.End()
+		}, "1:8: expected end of input but found '2'"},
+	}
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			assert.EqualError(t, test.read(NewReader([]byte(test.input))), test.expected)
+		})
+	}
+}
+
+func TestReaderTypeMismatchError(t *testing.T) {
+	r := NewReader([]byte(`{servers: [{port: 'x'}], hosts: 'a'}`))
+	err := r.ReadObject(func(key string) error {
+		if key == "servers" {
+			return r.ReadArray(func(int) error {
+				return r.ReadObject(func(string) error {
+					_, err := r.ReadInt(0)
+					return err
+				})
+			})
+		}
+		return r.Skip()
+	})
+	var mismatch *TypeMismatchError
+	assert.ErrorAs(t, err, &mismatch)
+	assert.Equal(t, TypeMismatchError{Value: "string", Type: "int", Path: "$.servers[0].port"}, *mismatch)
+
+	r = NewReader([]byte(`{hosts: 'a'}`))
+	err = r.ReadObject(func(string) error {
+		return r.ReadArray(func(int) error { return r.Skip() })
+	})
+	assert.ErrorAs(t, err, &mismatch)
+	assert.Equal(t, TypeMismatchError{Value: "string", Type: "array", Path: "$.hosts"}, *mismatch)
+}
+
+func TestReaderSetOptions(t *testing.T) {
+	r := NewReader([]byte(`{a: 1}`))
+	r.SetOptions(ParseOptions{JSON: true})
+	err := r.ReadObject(func(string) error { return r.Skip() })
+	assert.Error(t, err)
+}
+
+func TestUnmarshalReaderFrom(t *testing.T) {
+	var p point
+	assert.NoError(t, Unmarshal([]byte(`{x: 1, y: 2, z: 3}`), &p))
+	assert.Equal(t, point{1, 2}, p)
+	assert.EqualError(t, Unmarshal([]byte(`{x: 'a'}`), &p), "$.x: cannot unmarshal string into Go value of type int")
+	assert.EqualError(t, Unmarshal([]byte(`{x: 1} {}`), &p), "1:8: expected end of input but found '{'")
+	assert.Error(t, UnmarshalWithOptions([]byte(`{x: 1}`), &p, ParseOptions{JSON: true}))
+}
+
+func TestReaderMatchKey(t *testing.T) {
+	r := NewReader(nil)
+	assert.Equal(t, "name", r.MatchKey("name", "id", "name"))
+	assert.Equal(t, "name", r.MatchKey("NAME", "id", "name"))
+	assert.Equal(t, "Name", r.MatchKey("Name", "name", "Name"))
+	assert.Equal(t, "other", r.MatchKey("other", "id", "name"))
+}
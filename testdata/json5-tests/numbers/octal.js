@@ -0,0 +1 @@
+010
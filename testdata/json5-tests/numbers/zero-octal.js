@@ -0,0 +1 @@
+00
@@ -1,10 +1,14 @@
 package json5
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf16"
 	"unicode/utf8"
 )
 
@@ -19,25 +23,62 @@ const (
 	TOKEN_COLON                     // :
 	TOKEN_COMMA                     // ,
 	TOKEN_STRING                    // string (quoted or unquoted)
-	TOKEN_NUMBER                    // number (including hex)
+	TOKEN_NUMBER                    // number (including hex, Infinity and NaN)
 	TOKEN_TRUE                      // true
 	TOKEN_FALSE                     // false
 	TOKEN_NULL                      // null
 	TOKEN_COMMENT                   // comment
 	TOKEN_UNKNOWN                   // unknown
+	TOKEN_EOF                       // end of input
 )
 
-// Token represents a JSON5 token with its type and value
+// Position is a location in the input: a byte offset plus a line and column, both starting at 1.
+// Columns count bytes, like go/token does.
+type Position struct {
+	Offset int
+	Line   int
+	Column int
+}
+
+// String returns the position as "line:column"
+func (p Position) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Token represents a JSON5 token with its type, value and the span of input it was read from.
+// End is the position just past the last character of the token.
+// Quote is the quote character of a quoted TOKEN_STRING, and 0 for an unquoted identifier.
+// Err is set on TOKEN_UNKNOWN tokens for input that could not be tokenized, and is a *SyntaxError,
+// or a *LimitError once one of ParseOptions.Limits is exceeded.
 type Token struct {
 	Type  TokenType
 	Value string
+	Start Position
+	End   Position
+	Quote rune
+	Err   error
 }
 
-// isWhitespace checks if a character is a whitespace character
+// isWhitespace checks if a character is JSON5 whitespace: a line terminator, tab, vertical tab, form feed,
+// no-break space, byte order mark or any other Unicode space separator
 func isWhitespace(ch rune) bool {
+	switch ch {
+	case ' ', '\t', '\n', '\r', '\v', '\f', '\u00a0', '\ufeff', '\u2028', '\u2029':
+		return true
+	}
+	return unicode.Is(unicode.Zs, ch)
+}
+
+// isJSONWhitespace checks if a character is whitespace in plain JSON
+func isJSONWhitespace(ch rune) bool {
 	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r'
 }
 
+// isLineTerminator checks if a character ends a line
+func isLineTerminator(ch rune) bool {
+	return ch == '\n' || ch == '\r' || ch == '\u2028' || ch == '\u2029'
+}
+
 // isDigit checks if a character is a digit
 func isDigit(ch rune) bool {
 	return '0' <= ch && ch <= '9'
@@ -50,16 +91,19 @@ func isHexDigit(ch rune) bool {
 
 // isIdentifierStart checks if a character can be the start of an unquoted key
 func isIdentifierStart(ch rune) bool {
-	return unicode.IsLetter(ch) || ch == '_' || ch == '$'
+	return unicode.IsLetter(ch) || unicode.Is(unicode.Nl, ch) || ch == '_' || ch == '$'
 }
 
 // isIdentifierPart checks if a character can be part of an unquoted key
 func isIdentifierPart(ch rune) bool {
-	return unicode.IsLetter(ch) || unicode.IsDigit(ch) || ch == '_' || ch == '$'
+	return isIdentifierStart(ch) || unicode.IsDigit(ch) || unicode.In(ch, unicode.Mn, unicode.Mc, unicode.Pc) ||
+		ch == '\u200c' || ch == '\u200d'
 }
 
-// processEscapeSequences converts escape sequences such as \n, \t, \uXXXX, \UXXXXXXXX, \u{0x1FA}, and \U{0x1FA} into their actual representations
-func processEscapeSequences(input string) (string, error) {
+// processEscapeSequences converts escape sequences into their actual representations: the JSON5 single
+// character escapes (\n, \t, \b, \v, \0, ...), \xXX, \uXXXX including UTF-16 surrogate pairs, line
+// continuations, and the \UXXXXXXXX, \u{0x1FA} and \U{0x1FA} extensions. Any other escaped character stands for itself.
+func processEscapeSequences(input string, opts ParseOptions) (string, error) {
 	var result strings.Builder
 	length := len(input)
 
@@ -68,6 +112,17 @@ func processEscapeSequences(input string) (string, error) {
 
 		if ch == '\\' && i+1 < length {
 			nextCh := input[i+1]
+			// \u is a JSON escape but its braced form is not
+			if opts.JSON && nextCh == 'u' && i+2 < length && input[i+2] == '{' {
+				return "", fmt.Errorf("%w: \\u{...} escapes", ErrJSON5Only)
+			}
+			if opts.JSON && !strings.ContainsRune(`"\\/bfnrtu`, rune(nextCh)) {
+				escaped, _ := utf8.DecodeRuneInString(input[i+1:])
+				if isLineTerminator(escaped) {
+					return "", fmt.Errorf("%w: line continuations", ErrJSON5Only)
+				}
+				return "", fmt.Errorf("%w: \\%c escapes", ErrJSON5Only, escaped)
+			}
 			switch nextCh {
 			case 'n':
 				result.WriteByte('\n')
@@ -78,6 +133,15 @@ func processEscapeSequences(input string) (string, error) {
 			case 't':
 				result.WriteByte('\t')
 				i++
+			case 'b':
+				result.WriteByte('\b')
+				i++
+			case 'f':
+				result.WriteByte('\f')
+				i++
+			case 'v':
+				result.WriteByte('\v')
+				i++
 			case '\\':
 				result.WriteByte('\\')
 				i++
@@ -87,16 +151,45 @@ func processEscapeSequences(input string) (string, error) {
 			case '\'':
 				result.WriteByte('\'')
 				i++
+			case '0':
+				// \0 must not be followed by a digit, octal escapes are not allowed
+				if i+2 < length && isDigit(rune(input[i+2])) {
+					return "", fmt.Errorf("%w: \\0%c", ErrInvalidEscape, input[i+2])
+				}
+				result.WriteByte(0)
+				i++
+			case '1', '2', '3', '4', '5', '6', '7', '8', '9':
+				return "", fmt.Errorf("%w: \\%c", ErrInvalidEscape, nextCh)
+			case 'x':
+				// Handle \xXX
+				if i+3 >= length || !isHexDigit(rune(input[i+2])) || !isHexDigit(rune(input[i+3])) {
+					return "", fmt.Errorf("%w: \\x needs two hexadecimal digits", ErrInvalidEscape)
+				}
+				codePoint, _ := strconv.ParseUint(input[i+2:i+4], 16, 8)
+				result.WriteRune(rune(codePoint))
+				i += 3
+			case '\r':
+				// Line continuation, \r\n counts as a single line terminator
+				i++
+				if i+1 < length && input[i+1] == '\n' {
+					i++
+				}
+			case '\n':
+				// Line continuation
+				i++
 			case 'u', 'U': // Handle \uXXXX, \UXXXXXXXX, \u{0xXXXX}, \U{0xXXXX}
 				if i+2 < length && input[i+2] == '{' {
 					// Handle \u{...} or \U{...}
+					if !opts.allows(ExtBracedUnicodeEscape) {
+						return "", fmt.Errorf("%w: \\%c{...} escapes", ErrExtensionNotAllowed, nextCh)
+					}
 					i += 3
 					start := i
 					for i < length && input[i] != '}' {
 						i++
 					}
 					if i >= length {
-						return "", fmt.Errorf("invalid Unicode escape: incomplete \\u or \\U sequence")
+						return "", fmt.Errorf("%w: incomplete \\u or \\U sequence", ErrInvalidEscape)
 					}
 					hex := input[start:i]
 					if strings.HasPrefix(hex, "0x") {
@@ -104,32 +197,51 @@ func processEscapeSequences(input string) (string, error) {
 					}
 					codePoint, err := strconv.ParseInt(hex, 16, 32)
 					if err != nil || !utf8.ValidRune(rune(codePoint)) {
-						return "", fmt.Errorf("invalid Unicode escape: \\u{%s}", hex)
+						return "", fmt.Errorf("%w: \\u{%s}", ErrInvalidEscape, hex)
 					}
 					result.WriteRune(rune(codePoint))
 				} else if nextCh == 'u' && i+5 < length {
 					// Handle \uXXXX
-					hex := input[i+2 : i+6]
-					codePoint, err := strconv.ParseInt(hex, 16, 32)
-					if err != nil || !utf8.ValidRune(rune(codePoint)) {
-						return "", fmt.Errorf("invalid Unicode escape: \\u%s", hex)
+					codePoint, ok := parseHex4(input[i+2 : i+6])
+					if !ok {
+						return "", fmt.Errorf("%w: \\u%s", ErrInvalidEscape, input[i+2:i+6])
 					}
-					result.WriteRune(rune(codePoint))
 					i += 5 // Move past the 4 hex digits
+					if utf16.IsSurrogate(codePoint) {
+						// Combine a UTF-16 surrogate pair written as two consecutive escapes
+						if i+6 < length && input[i+1] == '\\' && input[i+2] == 'u' {
+							if low, ok := parseHex4(input[i+3 : i+7]); ok {
+								if pair := utf16.DecodeRune(codePoint, low); pair != utf8.RuneError {
+									codePoint = pair
+									i += 6
+								}
+							}
+						}
+						// A lone surrogate cannot be represented in UTF-8, WriteRune turns it into U+FFFD
+					}
+					result.WriteRune(codePoint)
+				} else if nextCh == 'U' && !opts.allows(ExtLongUnicodeEscape) {
+					return "", fmt.Errorf("%w: \\U escapes", ErrExtensionNotAllowed)
 				} else if nextCh == 'U' && i+9 < length {
 					// Handle \UXXXXXXXX
 					hex := input[i+2 : i+10]
 					codePoint, err := strconv.ParseInt(hex, 16, 32)
 					if err != nil || !utf8.ValidRune(rune(codePoint)) {
-						return "", fmt.Errorf("invalid Unicode escape: \\U%s", hex)
+						return "", fmt.Errorf("%w: \\U%s", ErrInvalidEscape, hex)
 					}
 					result.WriteRune(rune(codePoint))
 					i += 9 // Move past the 8 hex digits
 				} else {
-					return "", fmt.Errorf("invalid Unicode escape")
+					return "", fmt.Errorf("%w: incomplete Unicode escape", ErrInvalidEscape)
 				}
 			default:
-				result.WriteByte(ch)
+				// U+2028 and U+2029 are line terminators and form a line continuation,
+				// any other character is a NonEscapeCharacter and stands for itself
+				r, size := utf8.DecodeRuneInString(input[i+1:])
+				if r != '\u2028' && r != '\u2029' {
+					result.WriteString(input[i+1 : i+1+size])
+				}
+				i += size
 			}
 		} else {
 			result.WriteByte(ch)
@@ -139,129 +251,499 @@ func processEscapeSequences(input string) (string, error) {
 	return result.String(), nil
 }
 
-// tokenize is a function to tokenize a JSON5 string with unquoted key and hex number support, and escape sequence handling
-func Tokenize(input string) []Token {
-	var tokens []Token
-	length := len(input)
-	i := 0
+// parseHex4 parses the four hexadecimal digits of a \uXXXX escape
+func parseHex4(hex string) (rune, bool) {
+	for _, ch := range hex {
+		if !isHexDigit(ch) {
+			return 0, false
+		}
+	}
+	codePoint, err := strconv.ParseUint(hex, 16, 16)
+	return rune(codePoint), err == nil
+}
 
-	for i < length {
-		ch := rune(input[i])
+// lexer reads JSON5 tokens one at a time from a buffered reader
+type lexer struct {
+	r    *bufio.Reader
+	opts ParseOptions
+	err  error    // first read error other than io.EOF
+	pos  Position // position of the next rune
+	prev Position // position before the last read, restored by unread
 
-		// Skip whitespaces
-		if isWhitespace(ch) {
-			i++
+	afterCR     bool // the last rune read was '\r'
+	prevAfterCR bool // afterCR before the last read, restored by unread
+
+	start    int         // offset the MaxInputBytes limit counts from
+	tokens   int         // number of tokens scanned since start, for MaxTokens
+	limitErr *LimitError // limit exceeded, returned by every following call to next
+}
+
+// newLexer returns a lexer reading from r, buffering it if necessary
+func newLexer(r io.Reader) *lexer {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &lexer{r: br, pos: Position{Line: 1, Column: 1}}
+}
+
+// read returns the next rune of the input, or false at the end of the input
+func (l *lexer) read() (rune, bool) {
+	if l.limitErr != nil {
+		return 0, false
+	}
+	ch, size, err := l.r.ReadRune()
+	if err != nil {
+		if err != io.EOF && l.err == nil {
+			l.err = err
+		}
+		return 0, false
+	}
+	if lim := l.opts.Limits; lim != nil && lim.MaxInputBytes > 0 && int64(l.pos.Offset+size-l.start) > lim.MaxInputBytes {
+		l.r.UnreadRune()
+		l.limitErr = &LimitError{Position: l.pos, Limit: "MaxInputBytes", Max: lim.MaxInputBytes}
+		return 0, false
+	}
+	l.prev = l.pos
+	l.pos.Offset += size
+	if ch == '\n' && l.afterCR {
+		// The line break of a "\r\n" pair was already counted at the '\r'
+	} else if isLineTerminator(ch) {
+		l.pos.Line++
+		l.pos.Column = 1
+	} else {
+		l.pos.Column += size
+	}
+	l.prevAfterCR = l.afterCR
+	l.afterCR = ch == '\r'
+	return ch, true
+}
+
+// unread pushes the last rune returned by read back onto the input
+func (l *lexer) unread() {
+	l.r.UnreadRune()
+	l.pos = l.prev
+	l.afterCR = l.prevAfterCR
+}
+
+// peek returns the next rune of the input without consuming it
+func (l *lexer) peek() (rune, bool) {
+	ch, ok := l.read()
+	if ok {
+		l.unread()
+	}
+	return ch, ok
+}
+
+// next scans and returns the next token, or a TOKEN_EOF token at the end of the input
+func (l *lexer) next() Token {
+	for {
+		start := l.pos
+		ch, ok := l.read()
+		if l.limitErr != nil {
+			return l.limitToken()
+		}
+		if !ok {
+			return Token{Type: TOKEN_EOF, Start: start, End: start}
+		}
+
+		// Skip whitespaces, JSON mode only skips the JSON ones and leaves the others to scan
+		if isWhitespace(ch) && !(l.opts.JSON && !isJSONWhitespace(ch)) {
 			continue
 		}
 
-		// Handle single-line (//) and multi-line (/* */) comments
-		if ch == '/' && i+1 < length {
-			nextCh := rune(input[i+1])
-			if nextCh == '/' {
-				// Single-line comment
-				start := i
-				i += 2
-				for i < length && input[i] != '\n' {
-					i++
+		token := l.scan(ch)
+		if l.limitErr == nil {
+			l.tokens++
+			if lim := l.opts.Limits; lim != nil && lim.MaxTokens > 0 && l.tokens > lim.MaxTokens {
+				l.limitErr = &LimitError{Limit: "MaxTokens", Max: int64(lim.MaxTokens)}
+			}
+		}
+		if l.limitErr != nil {
+			if l.limitErr.Line == 0 {
+				l.limitErr.Position = start
+			}
+			return l.limitToken()
+		}
+		if l.opts.JSON && token.Type == TOKEN_NUMBER {
+			if feature := json5NumberFeature(token.Value); feature != "" {
+				token = json5OnlyToken(feature, token.Value)
+			}
+		}
+		token.Start = start
+		token.End = l.pos
+		if err, ok := token.Err.(*SyntaxError); ok {
+			err.Position = start
+		}
+		return token
+	}
+}
+
+// limitToken returns a TOKEN_UNKNOWN token carrying the limit exceeded
+func (l *lexer) limitToken() Token {
+	return Token{Type: TOKEN_UNKNOWN, Value: l.limitErr.Error(), Err: l.limitErr, Start: l.limitErr.Position, End: l.pos}
+}
+
+// tooLong records a MaxStringLength error when a string or identifier being scanned has reached n bytes
+func (l *lexer) tooLong(n int) bool {
+	if lim := l.opts.Limits; lim != nil && lim.MaxStringLength > 0 && n > lim.MaxStringLength {
+		l.limitErr = &LimitError{Limit: "MaxStringLength", Max: int64(lim.MaxStringLength)}
+		return true
+	}
+	return false
+}
+
+// resetLimits starts counting MaxInputBytes and MaxTokens afresh from the current position, for the next document
+func (l *lexer) resetLimits() {
+	l.start = l.pos.Offset
+	l.tokens = 0
+}
+
+// scan scans the token starting with ch, which has already been read
+func (l *lexer) scan(ch rune) Token {
+	if isWhitespace(ch) {
+		// Only reached in JSON mode
+		return json5OnlyToken(fmt.Sprintf("whitespace character U+%04X", ch), string(ch))
+	}
+
+	// Handle single-line (//) and multi-line (/* */) comments
+	if ch == '/' {
+		nextCh, _ := l.peek()
+		if (nextCh == '/' || nextCh == '*') && l.opts.JSON {
+			l.read()
+			return json5OnlyToken("comments", string(ch)+string(nextCh))
+		}
+		if nextCh == '/' {
+			// Single-line comment
+			var sb strings.Builder
+			sb.WriteRune(ch)
+			for {
+				ch, ok := l.read()
+				if !ok {
+					break
 				}
-				tokens = append(tokens, Token{Type: TOKEN_COMMENT, Value: input[start:i]})
-				continue
-			} else if nextCh == '*' {
-				// Multi-line comment
-				start := i
-				i += 2
-				for i < length-1 && !(input[i] == '*' && input[i+1] == '/') {
-					i++
+				if isLineTerminator(ch) {
+					l.unread()
+					break
+				}
+				sb.WriteRune(ch)
+			}
+			return Token{Type: TOKEN_COMMENT, Value: sb.String()}
+		} else if nextCh == '*' {
+			// Multi-line comment
+			var sb strings.Builder
+			sb.WriteRune(ch)
+			l.read()
+			sb.WriteRune(nextCh)
+			prev := rune(0)
+			for {
+				ch, ok := l.read()
+				if !ok {
+					return errorToken(ErrUnterminatedComment, "unterminated comment", sb.String())
 				}
-				i += 2 // Skip over the closing */
-				tokens = append(tokens, Token{Type: TOKEN_COMMENT, Value: input[start:i]})
-				continue
+				sb.WriteRune(ch)
+				if prev == '*' && ch == '/' {
+					break
+				}
+				prev = ch
 			}
+			return Token{Type: TOKEN_COMMENT, Value: sb.String()}
 		}
+	}
 
-		switch ch {
-		case '{':
-			tokens = append(tokens, Token{Type: TOKEN_LBRACE, Value: "{"})
-			i++
-		case '}':
-			tokens = append(tokens, Token{Type: TOKEN_RBRACE, Value: "}"})
-			i++
-		case '[':
-			tokens = append(tokens, Token{Type: TOKEN_LBRACKET, Value: "["})
-			i++
-		case ']':
-			tokens = append(tokens, Token{Type: TOKEN_RBRACKET, Value: "]"})
+	switch ch {
+	case '{':
+		return Token{Type: TOKEN_LBRACE, Value: "{"}
+	case '}':
+		return Token{Type: TOKEN_RBRACE, Value: "}"}
+	case '[':
+		return Token{Type: TOKEN_LBRACKET, Value: "["}
+	case ']':
+		return Token{Type: TOKEN_RBRACKET, Value: "]"}
+	case ':':
+		return Token{Type: TOKEN_COLON, Value: ":"}
+	case ',':
+		return Token{Type: TOKEN_COMMA, Value: ","}
+	case '"', '\'':
+		return l.scanString(ch)
+	}
+
+	if isDigit(ch) || ch == '-' || ch == '+' || ch == '.' {
+		return l.scanNumber(ch)
+	}
+
+	if isIdentifierStart(ch) || ch == '\\' {
+		return l.scanIdentifier(ch)
+	}
+
+	// Unknown token (for simplicity)
+	return Token{Type: TOKEN_UNKNOWN, Value: string(ch)}
+}
+
+// errorToken returns a TOKEN_UNKNOWN token carrying a *SyntaxError; next fills in its position
+func errorToken(err error, msg, found string) Token {
+	return Token{Type: TOKEN_UNKNOWN, Value: msg, Err: &SyntaxError{Err: err, Msg: msg, Found: found}}
+}
+
+// json5OnlyToken returns an error token for a JSON5 feature used in JSON mode
+func json5OnlyToken(feature, found string) Token {
+	return errorToken(ErrJSON5Only, ErrJSON5Only.Error()+": "+feature, found)
+}
+
+// scanString scans a quoted string (key or value) whose opening quote has already been read
+func (l *lexer) scanString(quote rune) Token {
+	if quote == '\'' && l.opts.JSON {
+		return json5OnlyToken("single-quoted strings", string(quote))
+	}
+	var raw strings.Builder
+	for {
+		ch, ok := l.read()
+		if !ok {
+			return errorToken(ErrUnterminatedString, "unterminated string", string(quote)+raw.String())
+		}
+		if ch == quote {
+			break
+		}
+		if ch < 0x20 && l.opts.JSON {
+			return json5OnlyToken("unescaped control characters in strings", string(quote)+raw.String())
+		}
+		if (ch == '\n' || ch == '\r') && !l.opts.allows(ExtRawLineBreaks) {
+			return errorToken(ErrExtensionNotAllowed, "non-standard extension not allowed: unescaped line break in string", string(quote)+raw.String())
+		}
+		raw.WriteRune(ch)
+		if l.tooLong(raw.Len()) {
+			return Token{Type: TOKEN_UNKNOWN}
+		}
+		if ch == '\\' {
+			// Escape sequence detected, keep the escaped character as is
+			escaped, ok := l.read()
+			if !ok {
+				return errorToken(ErrUnterminatedString, "unterminated string: truncated escape sequence", string(quote)+raw.String())
+			}
+			raw.WriteRune(escaped)
+			if escaped == '\r' {
+				// An escaped \r\n is a single line continuation
+				if next, ok := l.peek(); ok && next == '\n' {
+					l.read()
+					raw.WriteRune(next)
+				}
+			}
+		}
+	}
+	processedString, err := processEscapeSequences(raw.String(), l.opts) // Handle escape sequences
+	if err != nil {
+		// The error wraps the sentinel classifying it
+		return errorToken(errors.Unwrap(err), err.Error(), raw.String())
+	}
+	return Token{Type: TOKEN_STRING, Value: processedString, Quote: quote}
+}
+
+// scanNumber scans a number token whose first character (a digit, sign or dot) has already been read.
+// It accepts the JSON5 NumericLiteral grammar with an optional sign: decimals with optional leading
+// or trailing dot and signed exponent, hexadecimal integers, Infinity and NaN.
+func (l *lexer) scanNumber(first rune) Token {
+	var sb strings.Builder
+	sb.WriteRune(first)
+
+	if first == '+' || first == '-' {
+		if ch, ok := l.peek(); ok && isIdentifierStart(ch) {
+			// Signed Infinity or NaN
+			l.read()
+			word := l.scanIdentifier(ch)
+			if word.Type != TOKEN_NUMBER {
+				return errorToken(ErrInvalidNumber, fmt.Sprintf("invalid number: '%c%s'", first, word.Value), string(first)+word.Value)
+			}
+			return Token{Type: TOKEN_NUMBER, Value: string(first) + word.Value}
+		}
+	}
+
+	// Read the longest run of characters that can continue a number, then check it against the grammar,
+	// so that "1abc" or "0x" are reported as one invalid number rather than split into several tokens
+	hex := false
+	prev := first
+	for {
+		ch, ok := l.read()
+		if !ok {
+			break
+		}
+		if (ch == 'x' || ch == 'X') && prev == '0' && (sb.Len() == 1 || sb.Len() == 2 && (first == '+' || first == '-')) {
+			hex = true
+		} else if (ch == '+' || ch == '-') && (prev == 'e' || prev == 'E') && !hex {
+			// Signed exponent
+		} else if !(isIdentifierPart(ch) || ch == '.') {
+			l.unread()
+			break
+		}
+		sb.WriteRune(ch)
+		prev = ch
+	}
+
+	number := sb.String()
+	if !isNumberLiteral(number) {
+		return errorToken(ErrInvalidNumber, fmt.Sprintf("invalid number: '%s'", number), number)
+	}
+	return Token{Type: TOKEN_NUMBER, Value: number}
+}
+
+// json5NumberFeature names the JSON5 addition a valid number literal uses, or returns "" for a plain JSON number
+func json5NumberFeature(s string) string {
+	unsigned := strings.TrimPrefix(s, "-")
+	switch {
+	case strings.HasPrefix(s, "+"):
+		return "explicit plus sign"
+	case unsigned == "Infinity" || unsigned == "NaN":
+		return "Infinity and NaN"
+	case strings.HasPrefix(unsigned, "0x") || strings.HasPrefix(unsigned, "0X"):
+		return "hexadecimal numbers"
+	case strings.HasPrefix(unsigned, "."):
+		return "leading decimal point"
+	}
+	if dot := strings.IndexByte(unsigned, '.'); dot >= 0 && (dot+1 == len(unsigned) || !isDigit(rune(unsigned[dot+1]))) {
+		return "trailing decimal point"
+	}
+	return ""
+}
+
+// isNumberLiteral checks if s is a JSON5 NumericLiteral, optionally preceded by a sign
+func isNumberLiteral(s string) bool {
+	if strings.HasPrefix(s, "+") || strings.HasPrefix(s, "-") {
+		s = s[1:]
+	}
+	if s == "Infinity" || s == "NaN" {
+		return true
+	}
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		if len(s) == 2 {
+			return false
+		}
+		for _, ch := range s[2:] {
+			if !isHexDigit(ch) {
+				return false
+			}
+		}
+		return true
+	}
+
+	i := 0
+	// Integer part: a single zero or digits without a leading zero
+	intDigits := 0
+	if i < len(s) && s[i] == '0' {
+		i++
+		intDigits = 1
+	} else {
+		for i < len(s) && isDigit(rune(s[i])) {
 			i++
-		case ':':
-			tokens = append(tokens, Token{Type: TOKEN_COLON, Value: ":"})
+			intDigits++
+		}
+	}
+	// Fraction part, either side of the dot may be empty but not both
+	fracDigits := 0
+	if i < len(s) && s[i] == '.' {
+		i++
+		for i < len(s) && isDigit(rune(s[i])) {
 			i++
-		case ',':
-			tokens = append(tokens, Token{Type: TOKEN_COMMA, Value: ","})
+			fracDigits++
+		}
+	}
+	if intDigits == 0 && fracDigits == 0 {
+		return false
+	}
+	// Exponent part
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
 			i++
-		case '"', '\'':
-			// String token (quoted), handle both keys and values
-			start := i
-			quote := ch
+		}
+		expDigits := 0
+		for i < len(s) && isDigit(rune(s[i])) {
 			i++
-			for i < length && rune(input[i]) != quote {
-				if input[i] == '\\' && i+1 < length {
-					// Escape sequence detected, skip it
-					i += 2
-				} else {
-					i++
-				}
-			}
-			i++                                                       // Skip the closing quote
-			rawString := input[start+1 : i-1]                         // Remove quotes
-			processedString, err := processEscapeSequences(rawString) // Handle escape sequences
-			if err != nil {
-				tokens = append(tokens, Token{Type: TOKEN_UNKNOWN, Value: err.Error()})
-			} else {
-				tokens = append(tokens, Token{Type: TOKEN_STRING, Value: processedString})
-			}
-		default:
-			if isDigit(ch) || ch == '-' {
-				// Number token, including support for hex
-				start := i
-				if input[i:i+2] == "0x" || input[i:i+2] == "0X" {
-					// Hexadecimal number
-					i += 2
-					for i < length && isHexDigit(rune(input[i])) {
-						i++
-					}
-					tokens = append(tokens, Token{Type: TOKEN_NUMBER, Value: input[start:i]})
-				} else {
-					// Decimal number
-					for i < length && (isDigit(rune(input[i])) || input[i] == '.' || input[i] == 'e' || input[i] == 'E') {
-						i++
-					}
-					tokens = append(tokens, Token{Type: TOKEN_NUMBER, Value: input[start:i]})
-				}
-			} else if isIdentifierStart(ch) {
-				// Unquoted key or identifier
-				start := i
-				for i < length && isIdentifierPart(rune(input[i])) {
-					i++
-				}
-				unquotedString := input[start:i]
-				// Check if it's a boolean or null literal
-				switch unquotedString {
-				case "true":
-					tokens = append(tokens, Token{Type: TOKEN_TRUE, Value: "true"})
-				case "false":
-					tokens = append(tokens, Token{Type: TOKEN_FALSE, Value: "false"})
-				case "null":
-					tokens = append(tokens, Token{Type: TOKEN_NULL, Value: "null"})
-				default:
-					tokens = append(tokens, Token{Type: TOKEN_STRING, Value: unquotedString})
-				}
-			} else {
-				// Unknown token (for simplicity)
-				tokens = append(tokens, Token{Type: TOKEN_UNKNOWN, Value: string(ch)})
-				i++
+			expDigits++
+		}
+		if expDigits == 0 {
+			return false
+		}
+	}
+	return i == len(s)
+}
+
+// scanIdentifier scans an unquoted key or identifier whose first character has already been read
+// Identifiers may contain \uXXXX escapes, which are replaced by the character they stand for.
+func (l *lexer) scanIdentifier(first rune) Token {
+	var sb strings.Builder
+	escaped := false
+	for ch, ok := first, true; ok; ch, ok = l.read() {
+		start := sb.Len() == 0
+		if ch == '\\' {
+			r, valid := l.readIdentifierEscape()
+			if !valid || (start && !isIdentifierStart(r)) || (!start && !isIdentifierPart(r)) {
+				return errorToken(ErrInvalidEscape, "invalid escape sequence in identifier", sb.String())
 			}
+			ch = r
+			escaped = true
+		} else if !start && !isIdentifierPart(ch) {
+			l.unread()
+			break
+		}
+		sb.WriteRune(ch)
+		if l.tooLong(sb.Len()) {
+			return Token{Type: TOKEN_UNKNOWN}
+		}
+	}
+	unquotedString := sb.String()
+	if escaped {
+		// An escaped identifier is never a literal
+		return Token{Type: TOKEN_STRING, Value: unquotedString}
+	}
+	// Check if it's a boolean, null or numeric literal
+	switch unquotedString {
+	case "true":
+		return Token{Type: TOKEN_TRUE, Value: "true"}
+	case "false":
+		return Token{Type: TOKEN_FALSE, Value: "false"}
+	case "null":
+		return Token{Type: TOKEN_NULL, Value: "null"}
+	case "Infinity", "NaN":
+		return Token{Type: TOKEN_NUMBER, Value: unquotedString}
+	default:
+		return Token{Type: TOKEN_STRING, Value: unquotedString}
+	}
+}
+
+// readIdentifierEscape reads the uXXXX part of a \uXXXX escape in an identifier, after the backslash
+func (l *lexer) readIdentifierEscape() (rune, bool) {
+	if ch, ok := l.read(); !ok || ch != 'u' {
+		return 0, false
+	}
+	var hex strings.Builder
+	for i := 0; i < 4; i++ {
+		ch, ok := l.read()
+		if !ok {
+			return 0, false
 		}
+		hex.WriteRune(ch)
 	}
+	return parseHex4(hex.String())
+}
 
-	return tokens
+// Tokenize splits a JSON5 string into tokens, with unquoted key and hex number support, and escape sequence handling
+func Tokenize(input string) []Token {
+	return TokenizeWithOptions(input, ParseOptions{})
+}
+
+// TokenizeWithOptions is Tokenize with the syntax accepted controlled by opts. When one of opts.Limits
+// is exceeded, the last token returned carries the *LimitError.
+func TokenizeWithOptions(input string, opts ParseOptions) []Token {
+	var tokens []Token
+	l := newLexer(strings.NewReader(input))
+	l.opts = opts
+	for {
+		token := l.next()
+		if token.Type == TOKEN_EOF {
+			return tokens
+		}
+		tokens = append(tokens, token)
+		if l.limitErr != nil {
+			return tokens
+		}
+	}
 }
@@ -0,0 +1,87 @@
+package json5
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokenizePositions(t *testing.T) {
+	input := "{\n  key: 'välue', // note\n\tn: 0x1F\n}"
+
+	tokens := Tokenize(input)
+	expected := []Token{
+		{Type: TOKEN_LBRACE, Value: "{", Start: Position{0, 1, 1}, End: Position{1, 1, 2}},
+		{Type: TOKEN_STRING, Value: "key", Start: Position{4, 2, 3}, End: Position{7, 2, 6}},
+		{Type: TOKEN_COLON, Value: ":", Start: Position{7, 2, 6}, End: Position{8, 2, 7}},
+		{Type: TOKEN_STRING, Value: "välue", Start: Position{9, 2, 8}, End: Position{17, 2, 16}, Quote: '\''},
+		{Type: TOKEN_COMMA, Value: ",", Start: Position{17, 2, 16}, End: Position{18, 2, 17}},
+		{Type: TOKEN_COMMENT, Value: "// note", Start: Position{19, 2, 18}, End: Position{26, 2, 25}},
+		{Type: TOKEN_STRING, Value: "n", Start: Position{28, 3, 2}, End: Position{29, 3, 3}},
+		{Type: TOKEN_COLON, Value: ":", Start: Position{29, 3, 3}, End: Position{30, 3, 4}},
+		{Type: TOKEN_NUMBER, Value: "0x1F", Start: Position{31, 3, 5}, End: Position{35, 3, 9}},
+		{Type: TOKEN_RBRACE, Value: "}", Start: Position{36, 4, 1}, End: Position{37, 4, 2}},
+	}
+	assert.Equal(t, expected, tokens)
+}
+
+func TestTokenizePositionsLineTerminators(t *testing.T) {
+	tokens := Tokenize("1\r\n2\r3\n4")
+
+	assert.Len(t, tokens, 4)
+	for i, token := range tokens {
+		assert.Equal(t, i+1, token.Start.Line)
+		assert.Equal(t, 1, token.Start.Column)
+	}
+}
+
+func TestTokenizeUnterminated(t *testing.T) {
+	tests := []struct {
+		input    string
+		sentinel error
+	}{
+		{`"abc\"def"`, ErrUnterminatedString},
+		{`'it\'s'`, ErrUnterminatedString},
+		{`"\u0041"`, ErrUnterminatedString},
+		{`/* block * comment */`, ErrUnterminatedComment},
+	}
+
+	for _, test := range tests {
+		// Every truncation after the opening delimiter must end in an error token
+		for i := 2; i < len(test.input); i++ {
+			input := test.input[:i]
+			tokens := Tokenize(input)
+			last := tokens[len(tokens)-1]
+			assert.Equal(t, TOKEN_UNKNOWN, last.Type, input)
+			assert.ErrorIs(t, last.Err, test.sentinel, input)
+		}
+
+		tokens := Tokenize(test.input)
+		assert.Len(t, tokens, 1, test.input)
+		assert.NoError(t, tokens[0].Err, test.input)
+	}
+}
+
+func TestTokenizeTruncatedEscape(t *testing.T) {
+	tokens := Tokenize(`"abc\`)
+
+	assert.Len(t, tokens, 1)
+	assert.ErrorIs(t, tokens[0].Err, ErrUnterminatedString)
+	assert.Equal(t, "unterminated string: truncated escape sequence", tokens[0].Value)
+}
+
+func TestTokenizeNumbers(t *testing.T) {
+	tokens := Tokenize(`[+1, .5, 5., 1e+10, -0x1F, -Infinity, NaN, 1abc]`)
+
+	var values []string
+	for _, token := range tokens {
+		if token.Type == TOKEN_NUMBER {
+			values = append(values, token.Value)
+		}
+	}
+	assert.Equal(t, []string{"+1", ".5", "5.", "1e+10", "-0x1F", "-Infinity", "NaN"}, values)
+
+	last := tokens[len(tokens)-2]
+	assert.Equal(t, TOKEN_UNKNOWN, last.Type)
+	assert.ErrorIs(t, last.Err, ErrInvalidNumber)
+}
@@ -0,0 +1,399 @@
+package json5
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/shoobyban/json5/internal/jsonpath"
+)
+
+// Unmarshaler is implemented by types that decode their own JSON5 form.
+// UnmarshalJSON5 receives the source of a single JSON5 value, as written; it is not called for null.
+type Unmarshaler interface {
+	UnmarshalJSON5(data []byte) error
+}
+
+// source is the input values were parsed from, so that Unmarshaler methods and generated readers get the
+// text of their value as written
+type source struct {
+	data  string
+	path  []interface{} // path of the value data holds
+	opts  ParseOptions
+	spans map[string]span // where the values are in data by path, recorded on first use
+}
+
+// span is the start and end offsets of a value in its input
+type span struct {
+	start, end int
+}
+
+// text returns the source of the value at path, or the value written back as JSON5 if there is no source,
+// as for the list of values read with AllowMultipleValues
+func (s *source) text(value interface{}, path []interface{}) (string, error) {
+	if s == nil {
+		return Marshal(value, MarshalOptions{SortKeys: true})
+	}
+	if s.spans == nil {
+		// data has already been parsed within the limits of the caller
+		lex := newLexer(strings.NewReader(s.data))
+		lex.opts = s.opts
+		lex.opts.Limits = nil
+		p := newParser(lex)
+		p.path = append([]interface{}(nil), s.path...)
+		p.spans = make(map[string]span)
+		if _, err := p.parseDocument(); err != nil {
+			return "", err
+		}
+		s.spans = p.spans
+	}
+	if sp, ok := s.spans[jsonpath.Format(path)]; ok {
+		return s.data[sp.start:sp.end], nil
+	}
+	return Marshal(value, MarshalOptions{SortKeys: true})
+}
+
+// callUnmarshaler passes the source of a decoded value to u
+func callUnmarshaler(value interface{}, u Unmarshaler, path []interface{}, src *source) error {
+	data, err := src.text(value, path)
+	if err != nil {
+		return err
+	}
+	return wrapUnmarshalerError(u.UnmarshalJSON5([]byte(data)), path)
+}
+
+// callTextUnmarshaler passes a decoded string to u, rejecting any other value
+func callTextUnmarshaler(value interface{}, u encoding.TextUnmarshaler, path []interface{}) error {
+	s, ok := value.(string)
+	if !ok {
+		return typeMismatch(value, path, fmt.Sprintf("%T", u))
+	}
+	return wrapUnmarshalerError(u.UnmarshalText([]byte(s)), path)
+}
+
+// wrapUnmarshalerError adds path to an error returned by an UnmarshalJSON5 or UnmarshalText method,
+// unless it is a type error, which has a path already
+func wrapUnmarshalerError(err error, path []interface{}) error {
+	var typeErr interface{ typeError() }
+	if err == nil || errors.As(err, &typeErr) {
+		return err
+	}
+	return fmt.Errorf("%s: %w", jsonpath.Format(path), err)
+}
+
+// unmarshalTyped stores value, as returned by UnMarshal, into v when v points to one of the common types
+// handled without reflection: interface{}, string, bool, int, float64, []interface{}, map[string]interface{},
+// Number, big.Int, []string, []int, []float64, map[string]string, map[string]int and []map[string]interface{}, or implements
+// Unmarshaler or encoding.TextUnmarshaler. It returns false for any other type.
+func unmarshalTyped(value interface{}, v interface{}, path []interface{}, src *source) (bool, error) {
+	var err error
+	switch p := v.(type) {
+	case readerFrom:
+		if value != nil {
+			err = readFrom(value, p, path, src)
+		}
+	case Unmarshaler:
+		if value != nil {
+			err = callUnmarshaler(value, p, path, src)
+		}
+	case *big.Int:
+		if _, ok := value.(string); ok {
+			err = callTextUnmarshaler(value, p, path)
+		} else if value != nil {
+			err = decodeBigInt(value, p, path)
+		}
+	case encoding.TextUnmarshaler:
+		if value != nil {
+			err = callTextUnmarshaler(value, p, path)
+		}
+	case *interface{}:
+		*p = value
+	case *string:
+		err = decodeScalar(value, p, path, asString)
+	case *bool:
+		err = decodeScalar(value, p, path, asBool)
+	case *int:
+		err = decodeScalar(value, p, path, asInt)
+	case *float64:
+		err = decodeScalar(value, p, path, asFloat64)
+	case *Number:
+		err = decodeScalar(value, p, path, asNumber)
+	case *[]interface{}:
+		err = decodeSlice(value, p, path, func(item interface{}, path []interface{}) (interface{}, error) { return item, nil })
+	case *map[string]interface{}:
+		err = decodeMap(value, p, path, func(item interface{}, path []interface{}) (interface{}, error) { return item, nil })
+	case *[]string:
+		err = decodeSlice(value, p, path, asString)
+	case *[]int:
+		err = decodeSlice(value, p, path, asInt)
+	case *[]float64:
+		err = decodeSlice(value, p, path, asFloat64)
+	case *map[string]string:
+		err = decodeMap(value, p, path, asString)
+	case *map[string]int:
+		err = decodeMap(value, p, path, asInt)
+	case *[]map[string]interface{}:
+		err = decodeSlice(value, p, path, asObject)
+	default:
+		return false, nil
+	}
+	return true, err
+}
+
+// decodeScalar stores a scalar into p with convert; null leaves p unchanged
+func decodeScalar[T any](value interface{}, p *T, path []interface{}, convert func(interface{}, []interface{}) (T, error)) error {
+	if value == nil {
+		return nil
+	}
+	v, err := convert(value, path)
+	if err != nil {
+		return err
+	}
+	*p = v
+	return nil
+}
+
+// decodeSlice stores an array into p, converting each item with convert; null stores a nil slice
+func decodeSlice[T any](value interface{}, p *[]T, path []interface{}, convert func(interface{}, []interface{}) (T, error)) error {
+	if value == nil {
+		*p = nil
+		return nil
+	}
+	items, ok := value.([]interface{})
+	if !ok {
+		return typeMismatch(value, path, fmt.Sprintf("%T", *p))
+	}
+	result := make([]T, len(items))
+	for i, item := range items {
+		v, err := convert(item, append(path[:len(path):len(path)], i))
+		if err != nil {
+			return err
+		}
+		result[i] = v
+	}
+	*p = result
+	return nil
+}
+
+// decodeMap stores an object into p, converting each value with convert; null stores a nil map
+func decodeMap[T any](value interface{}, p *map[string]T, path []interface{}, convert func(interface{}, []interface{}) (T, error)) error {
+	if value == nil {
+		*p = nil
+		return nil
+	}
+	obj, err := asObject(value, path)
+	if err != nil {
+		return typeMismatch(value, path, fmt.Sprintf("%T", *p))
+	}
+	result := make(map[string]T, len(obj))
+	for key, member := range obj {
+		v, err := convert(member, append(path[:len(path):len(path)], key))
+		if err != nil {
+			return err
+		}
+		result[key] = v
+	}
+	*p = result
+	return nil
+}
+
+// asString converts a decoded value into a string
+func asString(value interface{}, path []interface{}) (string, error) {
+	s, ok := value.(string)
+	if !ok {
+		return "", typeMismatch(value, path, "string")
+	}
+	return s, nil
+}
+
+// asBool converts a decoded value into a bool
+func asBool(value interface{}, path []interface{}) (bool, error) {
+	b, ok := value.(bool)
+	if !ok {
+		return false, typeMismatch(value, path, "bool")
+	}
+	return b, nil
+}
+
+// asInt converts a decoded number into an int, rejecting fractions and numbers out of range
+func asInt(value interface{}, path []interface{}) (int, error) {
+	n, ok := toInt64(value)
+	if !ok || n < math.MinInt || n > math.MaxInt {
+		return 0, typeMismatch(value, path, "int")
+	}
+	return int(n), nil
+}
+
+// asFloat64 converts a decoded number into a float64
+func asFloat64(value interface{}, path []interface{}) (float64, error) {
+	f, ok := toFloat64(value)
+	if !ok {
+		return 0, typeMismatch(value, path, "float64")
+	}
+	return f, nil
+}
+
+// toInt64 converts a decoded number into an int64 if it is integral and in range
+func toInt64(value interface{}) (int64, bool) {
+	switch n := numberValue(value).(type) {
+	case int:
+		return int64(n), true
+	case int64:
+		return n, true
+	case uint64:
+		return int64(n), n <= math.MaxInt64
+	case *big.Int:
+		return n.Int64(), n.IsInt64()
+	case float64:
+		if n == math.Trunc(n) && n >= math.MinInt64 && n < math.MaxInt64 {
+			return int64(n), true
+		}
+	}
+	return 0, false
+}
+
+// toUint64 converts a decoded number into a uint64 if it is integral, non-negative and in range
+func toUint64(value interface{}) (uint64, bool) {
+	switch n := numberValue(value).(type) {
+	case int:
+		return uint64(n), n >= 0
+	case int64:
+		return uint64(n), n >= 0
+	case uint64:
+		return n, true
+	case *big.Int:
+		return n.Uint64(), n.IsUint64()
+	case float64:
+		if n == math.Trunc(n) && n >= 0 && n < math.MaxUint64 {
+			return uint64(n), true
+		}
+	}
+	return 0, false
+}
+
+// toFloat64 converts a decoded number into the nearest float64; integers too large for a float64 fail
+func toFloat64(value interface{}) (float64, bool) {
+	switch n := numberValue(value).(type) {
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case *big.Int:
+		f, _ := new(big.Float).SetInt(n).Float64()
+		return f, !math.IsInf(f, 0)
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// decodeBigInt stores an integral number into b
+func decodeBigInt(value interface{}, b *big.Int, path []interface{}) error {
+	switch n := numberValue(value).(type) {
+	case int:
+		b.SetInt64(int64(n))
+	case int64:
+		b.SetInt64(n)
+	case uint64:
+		b.SetUint64(n)
+	case *big.Int:
+		b.Set(n)
+	case float64:
+		if n != math.Trunc(n) || math.IsInf(n, 0) {
+			return typeMismatch(value, path, "*big.Int")
+		}
+		new(big.Float).SetFloat64(n).Int(b)
+	default:
+		return typeMismatch(value, path, "*big.Int")
+	}
+	return nil
+}
+
+// asNumber converts a decoded number into a Number
+func asNumber(value interface{}, path []interface{}) (Number, error) {
+	n, ok := toNumber(value)
+	if !ok {
+		return "", typeMismatch(value, path, "json5.Number")
+	}
+	return n, nil
+}
+
+// toNumber converts a decoded number into a Number, formatting it as Marshal does unless it already is one
+func toNumber(value interface{}) (Number, bool) {
+	switch n := value.(type) {
+	case Number:
+		return n, true
+	case int, int64, uint64, *big.Int, float64:
+		s, _ := Marshal(n)
+		return Number(s), true
+	}
+	return "", false
+}
+
+// numberValue converts a Number into the int, int64, uint64, *big.Int or float64 it stands for, leaving other values unchanged
+func numberValue(value interface{}) interface{} {
+	if n, ok := value.(Number); ok {
+		if v, err := n.value(); err == nil {
+			return v
+		}
+	}
+	return value
+}
+
+// asObject converts a decoded object into a map[string]interface{}, also accepting an *Object
+func asObject(value interface{}, path []interface{}) (map[string]interface{}, error) {
+	switch obj := value.(type) {
+	case map[string]interface{}:
+		return obj, nil
+	case *Object:
+		return obj.Map(), nil
+	}
+	return nil, typeMismatch(value, path, "map[string]interface {}")
+}
+
+// typeMismatch returns the error for a decoded value that does not fit the Go type typeName
+func typeMismatch(value interface{}, path []interface{}, typeName string) error {
+	return &TypeMismatchError{Value: describe(value), Type: typeName, Path: jsonpath.Format(path)}
+}
+
+// TypeMismatchError reports a value that does not fit the Go value it is decoded into without reflection, or
+// that is not the object or array a Reader expected. Unmarshal returns it for the common collection types and
+// on TinyGo; when it decodes through reflection it returns an *UnmarshalTypeError instead.
+type TypeMismatchError struct {
+	Value string // description of the JSON5 value, such as "string" or "number 1.5"
+	Type  string // Go type it could not be assigned to, or "object" or "array" for ReadObject and ReadArray
+	Path  string // JSON path of the value, such as $.servers[0].port
+}
+
+// Error returns the description prefixed with the JSON path of the value
+func (e *TypeMismatchError) Error() string {
+	if e.Type == "object" || e.Type == "array" {
+		return fmt.Sprintf("%s: expected %s but found %s", e.Path, e.Type, e.Value)
+	}
+	return fmt.Sprintf("%s: cannot unmarshal %s into Go value of type %s", e.Path, e.Value, e.Type)
+}
+
+func (*TypeMismatchError) typeError() {}
+
+// describe names the kind of a parsed JSON5 value for error messages
+func describe(value interface{}) string {
+	switch val := value.(type) {
+	case bool:
+		return "bool"
+	case string:
+		return "string"
+	case int, int64, uint64, *big.Int, float64, Number:
+		s, _ := Marshal(val)
+		return "number " + s
+	case []interface{}:
+		return "array"
+	case map[string]interface{}, *Object:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
@@ -0,0 +1,190 @@
+package json5
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshalTypedCollections(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{[]string{"a", "b\n"}, "[\n\"a\", \n\"b\\n\"\n]"},
+		{[]string{}, "[\n]"},
+		{[]string(nil), "null"},
+		{[]int{1, -2}, "[\n1, \n-2\n]"},
+		{[]float64{0.5, math.Inf(1)}, "[\n0.5, \nInfinity\n]"},
+		{[]map[string]interface{}{{"a": 1}, nil}, "[\n{\na: 1,\n}, \n{\n}\n]"},
+		{map[string]string{"b": "x", "a": "y"}, "{\na: \"y\",\nb: \"x\",\n}"},
+		{map[string]int{"b": 2, "a b": 1}, "{\n\"a b\": 1,\nb: 2,\n}"},
+		{map[string]int(nil), "null"},
+	}
+	for _, test := range tests {
+		result, err := Marshal(test.value)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, result)
+	}
+}
+
+func TestMarshalTypedCollectionsKeyLess(t *testing.T) {
+	reverse := func(a, b string) bool { return a > b }
+	result, err := Marshal(map[string]int{"a": 1, "b": 2}, MarshalOptions{KeyLess: reverse})
+	assert.NoError(t, err)
+	assert.Equal(t, "{\nb: 2,\na: 1,\n}", result)
+}
+
+func TestUnmarshalTyped(t *testing.T) {
+	decode := func(input string, v interface{}) error {
+		value, err := UnMarshal(input)
+		assert.NoError(t, err)
+		ok, err := unmarshalTyped(value, v, nil, nil)
+		assert.True(t, ok)
+		return err
+	}
+
+	var strs []string
+	assert.NoError(t, decode(`['a', "b"]`, &strs))
+	assert.Equal(t, []string{"a", "b"}, strs)
+
+	var ints []int
+	assert.NoError(t, decode(`[1, 0x10, 2e2]`, &ints))
+	assert.Equal(t, []int{1, 16, 200}, ints)
+
+	var floats []float64
+	assert.NoError(t, decode(`[1, .5, -Infinity]`, &floats))
+	assert.Equal(t, []float64{1, 0.5, math.Inf(-1)}, floats)
+
+	var strMap map[string]string
+	assert.NoError(t, decode(`{a: 'x'}`, &strMap))
+	assert.Equal(t, map[string]string{"a": "x"}, strMap)
+
+	var intMap map[string]int
+	assert.NoError(t, decode(`{a: 1, b: 2}`, &intMap))
+	assert.Equal(t, map[string]int{"a": 1, "b": 2}, intMap)
+
+	var objs []map[string]interface{}
+	assert.NoError(t, decode(`[{a: 1}, {}]`, &objs))
+	assert.Equal(t, []map[string]interface{}{{"a": 1}, {}}, objs)
+
+	var n int
+	assert.NoError(t, decode(`42`, &n))
+	assert.Equal(t, 42, n)
+
+	assert.NoError(t, decode(`null`, &strs))
+	assert.Nil(t, strs)
+
+	ok, err := unmarshalTyped(nil, &struct{}{}, nil, nil)
+	assert.False(t, ok)
+	assert.NoError(t, err)
+}
+
+func TestUnmarshalTypedErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		v     interface{}
+		msg   string
+	}{
+		{`['a', 1]`, new([]string), "$[1]: cannot unmarshal number 1 into Go value of type string"},
+		{`[1.5]`, new([]int), "$[0]: cannot unmarshal number 1.5 into Go value of type int"},
+		{`{a: 'x'}`, new([]int), "$: cannot unmarshal object into Go value of type []int"},
+		{`{a: true}`, new(map[string]int), "$.a: cannot unmarshal bool into Go value of type int"},
+		{`[1]`, new(map[string]string), "$: cannot unmarshal array into Go value of type map[string]string"},
+		{`[[]]`, new([]map[string]interface{}), "$[0]: cannot unmarshal array into Go value of type map[string]interface {}"},
+		{`'x'`, new(float64), "$: cannot unmarshal string into Go value of type float64"},
+	}
+	for _, test := range tests {
+		value, err := UnMarshal(test.input)
+		assert.NoError(t, err)
+		ok, err := unmarshalTyped(value, test.v, nil, nil)
+		assert.True(t, ok)
+		assert.EqualError(t, err, test.msg, test.input)
+	}
+}
+
+// testCelsius is decoded through Unmarshaler, testUnit through encoding.TextUnmarshaler
+type testCelsius float64
+
+func (c *testCelsius) UnmarshalJSON5(data []byte) error {
+	value, err := UnMarshal(strings.TrimSuffix(string(data), "C"))
+	if err != nil {
+		return err
+	}
+	f, err := asFloat64(value, nil)
+	*c = testCelsius(f)
+	return err
+}
+
+type testUnit string
+
+func (u *testUnit) UnmarshalText(text []byte) error {
+	*u = testUnit(strings.ToUpper(string(text)))
+	return nil
+}
+
+func TestUnmarshalTypedInterfaces(t *testing.T) {
+	var c testCelsius
+	ok, err := unmarshalTyped(21.5, &c, nil, nil)
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.Equal(t, testCelsius(21.5), c)
+
+	var u testUnit
+	ok, err = unmarshalTyped("kg", &u, nil, nil)
+	assert.True(t, ok)
+	assert.NoError(t, err)
+	assert.Equal(t, testUnit("KG"), u)
+
+	_, err = unmarshalTyped(1, &u, nil, nil)
+	assert.EqualError(t, err, "$: cannot unmarshal number 1 into Go value of type *json5.testUnit")
+}
+
+// testSource keeps the source of its value, as passed to UnmarshalJSON5
+type testSource string
+
+func (s *testSource) UnmarshalJSON5(data []byte) error {
+	*s = testSource(data)
+	return nil
+}
+
+// testVerbosity rejects every verbosity but "debug"
+type testVerbosity string
+
+func (l *testVerbosity) UnmarshalText(text []byte) error {
+	if string(text) != "debug" {
+		return errors.New("unknown level")
+	}
+	*l = testVerbosity(text)
+	return nil
+}
+
+func TestUnmarshalerSource(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected testSource
+	}{
+		{`0xFF`, "0xFF"},
+		{` 1.10 // price`, "1.10"},
+		{`/* header */ {b: 1, a: [2, /* c */ 3]}`, "{b: 1, a: [2, /* c */ 3]}"},
+		{`'it\'s'`, `'it\'s'`},
+	}
+	for _, test := range tests {
+		var s testSource
+		assert.NoError(t, Unmarshal([]byte(test.input), &s), test.input)
+		assert.Equal(t, test.expected, s, test.input)
+	}
+}
+
+func TestUnmarshalerErrorPath(t *testing.T) {
+	var l testVerbosity
+	assert.EqualError(t, Unmarshal([]byte(`'trace'`), &l), "$: unknown level")
+	assert.NoError(t, Unmarshal([]byte(`'debug'`), &l))
+	assert.Equal(t, testVerbosity("debug"), l)
+
+	var c testCelsius
+	assert.EqualError(t, Unmarshal([]byte(`'warm'`), &c), "$: cannot unmarshal string into Go value of type float64")
+}
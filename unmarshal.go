@@ -0,0 +1,368 @@
+//go:build !tinygo
+
+package json5
+
+import (
+	"encoding"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strconv"
+
+	"github.com/shoobyban/json5/internal/jsonpath"
+)
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// numberType is decoded from numbers only, although it is a string type
+var numberType = reflect.TypeOf(Number(""))
+
+// UnmarshalTypeError describes a JSON5 value that cannot be stored in a Go value of a specific type
+type UnmarshalTypeError struct {
+	Value string       // description of the JSON5 value, such as "string" or "number 1.5"
+	Type  reflect.Type // type of the Go value it could not be assigned to
+	Path  string       // JSON path of the value, such as $.servers[0].port
+}
+
+// Error returns the description prefixed with the JSON path of the value
+func (e *UnmarshalTypeError) Error() string {
+	return fmt.Sprintf("%s: cannot unmarshal %s into Go value of type %s", e.Path, e.Value, e.Type)
+}
+
+func (*UnmarshalTypeError) typeError() {}
+
+// InvalidUnmarshalError describes an invalid argument passed to Unmarshal, which needs a non-nil pointer
+type InvalidUnmarshalError struct {
+	Type reflect.Type
+}
+
+// Error returns the description of the invalid argument
+func (e *InvalidUnmarshalError) Error() string {
+	if e.Type == nil {
+		return "json5: Unmarshal(nil)"
+	}
+	if e.Type.Kind() != reflect.Pointer {
+		return "json5: Unmarshal(non-pointer " + e.Type.String() + ")"
+	}
+	return "json5: Unmarshal(nil " + e.Type.String() + ")"
+}
+
+// Unmarshal parses JSON5 data and stores the result in the value pointed to by v, which can be a struct,
+// slice, array, map, pointer or any other type a JSON5 value converts to. Struct fields are matched by
+// their json5 tag, their json tag or their name, as in encoding/json. Unknown keys are ignored.
+func Unmarshal(data []byte, v interface{}) error {
+	return UnmarshalWithOptions(data, v, ParseOptions{})
+}
+
+// UnmarshalWithOptions is Unmarshal with the syntax accepted controlled by opts
+func UnmarshalWithOptions(data []byte, v interface{}, opts ParseOptions) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &InvalidUnmarshalError{Type: reflect.TypeOf(v)}
+	}
+	if rf, ok := v.(readerFrom); ok && !opts.AllowMultipleValues {
+		return readDocument(data, rf, opts)
+	}
+	s := string(data)
+	value, err := UnMarshalWithOptions(s, opts)
+	if err != nil {
+		return err
+	}
+	return unmarshalValue(value, v, nil, &source{data: s, opts: opts})
+}
+
+// unmarshalValue stores a parsed value into the value pointed to by v; path locates it for error messages
+// and src holds its source, for Unmarshaler methods
+func unmarshalValue(value interface{}, v interface{}, path []interface{}, src *source) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return &InvalidUnmarshalError{Type: reflect.TypeOf(v)}
+	}
+	d := &decodeState{path: append([]interface{}(nil), path...), src: src}
+	return d.decode(value, rv.Elem())
+}
+
+// decodeState stores a parsed JSON5 value into Go values
+type decodeState struct {
+	path []interface{} // keys and indices leading to the value being stored, for error reporting
+	src  *source       // the input value was parsed from
+}
+
+// typeError returns an *UnmarshalTypeError for value at the current path
+func (d *decodeState) typeError(what string, t reflect.Type) error {
+	return &UnmarshalTypeError{Value: what, Type: t, Path: jsonpath.Format(d.path)}
+}
+
+// decode stores value, as returned by UnMarshal, into v
+func (d *decodeState) decode(value interface{}, v reflect.Value) error {
+	if value == nil {
+		switch v.Kind() {
+		case reflect.Interface, reflect.Pointer, reflect.Map, reflect.Slice:
+			v.Set(reflect.Zero(v.Type()))
+		}
+		return nil
+	}
+
+	v, u, tu := indirect(v)
+	if rf, ok := u.(readerFrom); ok {
+		return readFrom(value, rf, d.path, d.src)
+	}
+	if u != nil {
+		return callUnmarshaler(value, u, d.path, d.src)
+	}
+	if b, ok := tu.(*big.Int); ok && value != nil {
+		// Numbers go to big.Int directly, strings through its UnmarshalText
+		if _, ok := value.(string); !ok {
+			if decodeBigInt(value, b, d.path) != nil {
+				return d.typeError(describe(value), v.Type())
+			}
+			return nil
+		}
+	}
+	if tu != nil {
+		if _, ok := value.(string); !ok {
+			return d.typeError(describe(value), v.Type())
+		}
+		return callTextUnmarshaler(value, tu, d.path)
+	}
+	if v.Kind() == reflect.Interface {
+		if v.NumMethod() != 0 {
+			return d.typeError(describe(value), v.Type())
+		}
+		v.Set(reflect.ValueOf(value))
+		return nil
+	}
+
+	if v.Type() == numberType {
+		n, ok := toNumber(value)
+		if !ok {
+			return d.typeError(describe(value), v.Type())
+		}
+		v.SetString(string(n))
+		return nil
+	}
+
+	switch val := value.(type) {
+	case bool:
+		if v.Kind() != reflect.Bool {
+			return d.typeError("bool", v.Type())
+		}
+		v.SetBool(val)
+	case string:
+		if v.Kind() != reflect.String {
+			return d.typeError("string", v.Type())
+		}
+		v.SetString(val)
+	case int, int64, uint64, *big.Int, float64, Number:
+		return d.decodeNumber(val, v)
+	case []interface{}:
+		return d.decodeArray(val, v)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(val))
+		for key := range val {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return d.decodeObject(keys, val, v)
+	case *Object:
+		return d.decodeObject(val.keys, val.values, v)
+	default:
+		return d.typeError(describe(value), v.Type())
+	}
+	return nil
+}
+
+// indirect follows and allocates the pointers leading from v to a non-pointer value, stopping early at a
+// value whose pointer implements Unmarshaler or encoding.TextUnmarshaler
+func indirect(v reflect.Value) (reflect.Value, Unmarshaler, encoding.TextUnmarshaler) {
+	for {
+		if v.CanAddr() && v.Kind() != reflect.Pointer && v.Addr().CanInterface() {
+			switch u := v.Addr().Interface().(type) {
+			case Unmarshaler:
+				return v, u, nil
+			case encoding.TextUnmarshaler:
+				return v, nil, u
+			}
+		}
+		if v.Kind() != reflect.Pointer {
+			return v, nil, nil
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		v = v.Elem()
+	}
+}
+
+// decodeNumber stores a decoded number into a numeric v, rejecting values that do not fit
+func (d *decodeState) decodeNumber(value interface{}, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, ok := toInt64(value)
+		if !ok || v.OverflowInt(i) {
+			return d.typeError(describe(value), v.Type())
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, ok := toUint64(value)
+		if !ok || v.OverflowUint(u) {
+			return d.typeError(describe(value), v.Type())
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, ok := toFloat64(value)
+		if !ok || v.OverflowFloat(f) {
+			return d.typeError(describe(value), v.Type())
+		}
+		v.SetFloat(f)
+	default:
+		return d.typeError(describe(value), v.Type())
+	}
+	return nil
+}
+
+// decodeArray stores the items of an array into a slice or an array
+func (d *decodeState) decodeArray(items []interface{}, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), len(items), len(items)))
+	case reflect.Array:
+		// Items that do not fit are dropped, missing ones are zeroed
+		for i := len(items); i < v.Len(); i++ {
+			v.Index(i).Set(reflect.Zero(v.Type().Elem()))
+		}
+		if len(items) > v.Len() {
+			items = items[:v.Len()]
+		}
+	default:
+		return d.typeError("array", v.Type())
+	}
+
+	for i, item := range items {
+		d.path = append(d.path, i)
+		if err := d.decode(item, v.Index(i)); err != nil {
+			return err
+		}
+		d.path = d.path[:len(d.path)-1]
+	}
+	return nil
+}
+
+// decodeObject stores the members of an object into a struct or a map
+func (d *decodeState) decodeObject(keys []string, values map[string]interface{}, v reflect.Value) error {
+	switch v.Kind() {
+	case reflect.Struct:
+		fields := cachedFields(v.Type())
+		for _, key := range keys {
+			f, ok := fieldByName(fields, key)
+			if !ok {
+				continue
+			}
+			d.path = append(d.path, key)
+			fv, err := d.fieldByIndex(v, f.index)
+			if err != nil {
+				return err
+			}
+			if f.quoted {
+				err = d.decodeQuoted(values[key], fv)
+			} else {
+				err = d.decode(values[key], fv)
+			}
+			if err != nil {
+				return err
+			}
+			d.path = d.path[:len(d.path)-1]
+		}
+	case reflect.Map:
+		t := v.Type()
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(t))
+		}
+		for _, key := range keys {
+			d.path = append(d.path, key)
+			kv, err := d.mapKey(key, t.Key())
+			if err != nil {
+				return err
+			}
+			elem := reflect.New(t.Elem()).Elem()
+			if err := d.decode(values[key], elem); err != nil {
+				return err
+			}
+			v.SetMapIndex(kv, elem)
+			d.path = d.path[:len(d.path)-1]
+		}
+	default:
+		return d.typeError("object", v.Type())
+	}
+	return nil
+}
+
+// fieldByIndex returns the field of struct v at index, allocating the embedded pointers on the way
+func (d *decodeState) fieldByIndex(v reflect.Value, index []int) (reflect.Value, error) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !v.CanSet() {
+					return reflect.Value{}, fmt.Errorf("json5: cannot set embedded pointer to unexported struct %v", v.Type().Elem())
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, nil
+}
+
+// decodeQuoted stores a scalar written inside a string, for fields tagged with ",string"
+func (d *decodeState) decodeQuoted(value interface{}, v reflect.Value) error {
+	if value == nil {
+		return d.decode(nil, v)
+	}
+	s, ok := value.(string)
+	if !ok {
+		return d.typeError(describe(value), v.Type())
+	}
+	// Numbers are kept as written, so a Number field gets the exact literal
+	opts := ParseOptions{UseNumber: true}
+	inner, err := UnMarshalWithOptions(s, opts)
+	if err == nil {
+		src := d.src
+		d.src = &source{data: s, path: append([]interface{}(nil), d.path...), opts: opts}
+		err = d.decode(inner, v)
+		d.src = src
+	}
+	if err != nil {
+		return d.typeError("string "+strconv.Quote(s), v.Type())
+	}
+	return nil
+}
+
+// mapKey converts an object key into a map key of type t
+func (d *decodeState) mapKey(key string, t reflect.Type) (reflect.Value, error) {
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		kv := reflect.New(t)
+		if err := kv.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(key)); err != nil {
+			return reflect.Value{}, wrapUnmarshalerError(err, d.path)
+		}
+		return kv.Elem(), nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(key).Convert(t), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(key, 10, 64)
+		if err != nil || reflect.Zero(t).OverflowInt(n) {
+			return reflect.Value{}, d.typeError("number "+key, t)
+		}
+		return reflect.ValueOf(n).Convert(t), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(key, 10, 64)
+		if err != nil || reflect.Zero(t).OverflowUint(n) {
+			return reflect.Value{}, d.typeError("number "+key, t)
+		}
+		return reflect.ValueOf(n).Convert(t), nil
+	}
+	return reflect.Value{}, d.typeError("object key", t)
+}
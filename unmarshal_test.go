@@ -0,0 +1,243 @@
+//go:build !tinygo
+
+package json5
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testAddress struct {
+	City    string `json5:"city"`
+	Zipcode int    `json:"zip"`
+}
+
+type testBase struct {
+	ID      int `json5:"id"`
+	Created string
+}
+
+type testPerson struct {
+	testBase
+	Name       string            `json5:"name"`
+	Age        uint8             `json5:"age,omitempty"`
+	Height     float64           `json5:"height"`
+	Married    bool              `json5:"married"`
+	Nick       *string           `json5:"nick"`
+	Address    *testAddress      `json5:"address"`
+	Tags       []string          `json5:"tags"`
+	Scores     [3]int            `json5:"scores"`
+	Labels     map[string]string `json5:"labels"`
+	Counts     map[int]float64   `json5:"counts"`
+	Extra      interface{}       `json5:"extra"`
+	Port       int               `json5:"port,string"`
+	Ignored    string            `json5:"-"`
+	unexported string
+}
+
+func TestUnmarshalStruct(t *testing.T) {
+	input := `{
+		// comments and JSON5 syntax work as usual
+		id: 7,
+		created: 'today',
+		name: "John Doe",
+		age: 42,
+		height: 1.85,
+		married: true,
+		nick: 'JD',
+		address: {city: "New York", zip: 0x2711},
+		tags: ['a', 'b'],
+		scores: [1, 2, 3, 4],
+		labels: {env: "prod"},
+		counts: {"1": 1.5, "2": 2},
+		extra: [1, {x: null}],
+		port: "8080",
+		Ignored: "no",
+		unknown: "skipped",
+	}`
+
+	var p testPerson
+	err := Unmarshal([]byte(input), &p)
+	assert.NoError(t, err)
+
+	nick := "JD"
+	assert.Equal(t, testPerson{
+		testBase: testBase{ID: 7, Created: "today"},
+		Name:     "John Doe",
+		Age:      42,
+		Height:   1.85,
+		Married:  true,
+		Nick:     &nick,
+		Address:  &testAddress{City: "New York", Zipcode: 10001},
+		Tags:     []string{"a", "b"},
+		Scores:   [3]int{1, 2, 3},
+		Labels:   map[string]string{"env": "prod"},
+		Counts:   map[int]float64{1: 1.5, 2: 2},
+		Extra:    []interface{}{1, map[string]interface{}{"x": nil}},
+		Port:     8080,
+	}, p)
+}
+
+func TestUnmarshalNull(t *testing.T) {
+	nick := "JD"
+	p := testPerson{Name: "kept", Nick: &nick, Tags: []string{"a"}}
+	err := Unmarshal([]byte(`{nick: null, tags: null, name: null}`), &p)
+	assert.NoError(t, err)
+	assert.Nil(t, p.Nick)
+	assert.Nil(t, p.Tags)
+	assert.Equal(t, "kept", p.Name)
+}
+
+func TestUnmarshalEmbeddedPointer(t *testing.T) {
+	type inner struct {
+		A int
+	}
+	type Inner inner
+	type outer struct {
+		*Inner
+		B int
+	}
+	var o outer
+	err := Unmarshal([]byte(`{a: 1, b: 2}`), &o)
+	assert.NoError(t, err)
+	assert.Equal(t, outer{Inner: &Inner{A: 1}, B: 2}, o)
+}
+
+func TestUnmarshalFieldConflicts(t *testing.T) {
+	type A struct{ Name, Both string }
+	type B struct {
+		Name string `json5:"Name"`
+		Both string
+	}
+	type C struct {
+		A
+		B
+		Own  string
+		Name string `json5:"own"`
+	}
+	var c C
+	err := Unmarshal([]byte(`{Name: "x", Both: "y", own: "z", Own: "w"}`), &c)
+	assert.NoError(t, err)
+	// "Name" is tagged only in B, "Both" is ambiguous, "own" prefers the exact match
+	assert.Equal(t, C{B: B{Name: "x"}, Own: "w", Name: "z"}, c)
+}
+
+func TestUnmarshalScalars(t *testing.T) {
+	var i int
+	assert.NoError(t, Unmarshal([]byte("1e3"), &i))
+	assert.Equal(t, 1000, i)
+
+	var f float32
+	assert.NoError(t, Unmarshal([]byte("-Infinity"), &f))
+	assert.True(t, math.IsInf(float64(f), -1))
+
+	var s []interface{}
+	assert.NoError(t, Unmarshal([]byte("[1, 'two']"), &s))
+	assert.Equal(t, []interface{}{1, "two"}, s)
+
+	var obj interface{}
+	assert.NoError(t, UnmarshalWithOptions([]byte("{b: 1, a: 2}"), &obj, ParseOptions{OrderedObjects: true}))
+	assert.Equal(t, []string{"b", "a"}, obj.(*Object).Keys())
+}
+
+func TestUnmarshalTypeErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		msg   string
+		path  string
+	}{
+		{`{name: 1}`, "$.name: cannot unmarshal number 1 into Go value of type string", "$.name"},
+		{`{age: 300}`, "$.age: cannot unmarshal number 300 into Go value of type uint8", "$.age"},
+		{`{age: -1}`, "$.age: cannot unmarshal number -1 into Go value of type uint8", "$.age"},
+		{`{id: 1.5}`, "$.id: cannot unmarshal number 1.5 into Go value of type int", "$.id"},
+		{`{address: {zip: "x"}}`, "$.address.zip: cannot unmarshal string into Go value of type int", "$.address.zip"},
+		{`{tags: ['a', true]}`, "$.tags[1]: cannot unmarshal bool into Go value of type string", "$.tags[1]"},
+		{`{tags: {}}`, "$.tags: cannot unmarshal object into Go value of type []string", "$.tags"},
+		{`{counts: {x: 1}}`, "$.counts.x: cannot unmarshal number x into Go value of type int", "$.counts.x"},
+		{`{port: "eighty"}`, `$.port: cannot unmarshal string "eighty" into Go value of type int`, "$.port"},
+		{`{port: 80}`, "$.port: cannot unmarshal number 80 into Go value of type int", "$.port"},
+		{`[]`, "$: cannot unmarshal array into Go value of type json5.testPerson", "$"},
+	}
+
+	for _, test := range tests {
+		var p testPerson
+		err := Unmarshal([]byte(test.input), &p)
+		var typeErr *UnmarshalTypeError
+		if assert.True(t, errors.As(err, &typeErr), test.input) {
+			assert.EqualError(t, err, test.msg, test.input)
+			assert.Equal(t, test.path, typeErr.Path, test.input)
+		}
+	}
+}
+
+func TestUnmarshalInvalidArguments(t *testing.T) {
+	var p testPerson
+	var nilPtr *testPerson
+	tests := []struct {
+		v   interface{}
+		msg string
+	}{
+		{nil, "json5: Unmarshal(nil)"},
+		{p, "json5: Unmarshal(non-pointer json5.testPerson)"},
+		{nilPtr, "json5: Unmarshal(nil *json5.testPerson)"},
+	}
+	for _, test := range tests {
+		err := Unmarshal([]byte(`{}`), test.v)
+		var invalidErr *InvalidUnmarshalError
+		assert.True(t, errors.As(err, &invalidErr))
+		assert.EqualError(t, err, test.msg)
+	}
+}
+
+func TestUnmarshalSyntaxError(t *testing.T) {
+	var p testPerson
+	err := Unmarshal([]byte(`{name: }`), &p)
+	var syntaxErr *SyntaxError
+	assert.True(t, errors.As(err, &syntaxErr))
+	assert.True(t, errors.Is(err, ErrUnexpectedToken))
+	assert.Equal(t, testPerson{}, p)
+}
+
+func TestUnmarshalIntegerRange(t *testing.T) {
+	var u uint64
+	assert.NoError(t, Unmarshal([]byte(`18446744073709551615`), &u))
+	assert.Equal(t, uint64(math.MaxUint64), u)
+	var n int
+	assert.EqualError(t, Unmarshal([]byte(`9223372036854775808`), &n),
+		"$: cannot unmarshal number 9223372036854775808 into Go value of type int")
+	var f float64
+	assert.NoError(t, Unmarshal([]byte(`18446744073709551616`), &f))
+	assert.Equal(t, 18446744073709551616.0, f)
+
+	b := new(big.Int)
+	assert.NoError(t, Unmarshal([]byte(`-0x1FFFFFFFFFFFFFFFFF`), b))
+	assert.Equal(t, "-590295810358705651711", b.String())
+	assert.NoError(t, Unmarshal([]byte(`'12'`), b))
+	assert.Equal(t, "12", b.String())
+	assert.Error(t, Unmarshal([]byte(`1.5`), b))
+
+	r := NewReader([]byte(`[18446744073709551615, 18446744073709551616]`))
+	var items []uint64
+	err := r.ReadArray(func(int) error {
+		n, err := r.ReadUint(64)
+		items = append(items, n)
+		return err
+	})
+	assert.EqualError(t, err, "$[1]: cannot unmarshal number 18446744073709551616 into Go value of type uint64")
+	assert.Equal(t, []uint64{math.MaxUint64, 0}, items)
+}
+
+func TestUnmarshalMultipleValues(t *testing.T) {
+	type record struct{ A int }
+	var records []record
+	err := UnmarshalWithOptions([]byte("{A: 1}\n{A: 2}\n"), &records, ParseOptions{AllowMultipleValues: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []record{{1}, {2}}, records)
+
+	var single record
+	assert.EqualError(t, Unmarshal([]byte("{A: 1}\n{A: 2}\n"), &single), "2:1: expected end of input but found '{'")
+}
@@ -0,0 +1,38 @@
+//go:build tinygo
+
+package json5
+
+import (
+	"fmt"
+)
+
+// Unmarshal parses JSON5 data and stores the result in the value pointed to by v.
+// Without reflection, TinyGo builds support pointers to interface{}, string, bool, int, float64,
+// []interface{}, map[string]interface{}, []string, []int, []float64, map[string]string,
+// map[string]int and []map[string]interface{}.
+func Unmarshal(data []byte, v interface{}) error {
+	return UnmarshalWithOptions(data, v, ParseOptions{})
+}
+
+// UnmarshalWithOptions is Unmarshal with the syntax accepted controlled by opts
+func UnmarshalWithOptions(data []byte, v interface{}, opts ParseOptions) error {
+	if rf, ok := v.(readerFrom); ok && !opts.AllowMultipleValues {
+		return readDocument(data, rf, opts)
+	}
+	s := string(data)
+	value, err := UnMarshalWithOptions(s, opts)
+	if err != nil {
+		return err
+	}
+	return unmarshalValue(value, v, nil, &source{data: s, opts: opts})
+}
+
+// unmarshalValue stores a parsed value into the value pointed to by v, if it is one of the supported types;
+// path locates it for error messages and src holds its source, for Unmarshaler methods
+func unmarshalValue(value interface{}, v interface{}, path []interface{}, src *source) error {
+	ok, err := unmarshalTyped(value, v, path, src)
+	if !ok {
+		return fmt.Errorf("json5: Unmarshal(%T) is not supported without reflection", v)
+	}
+	return err
+}
@@ -0,0 +1,169 @@
+package json5
+
+import (
+	"strings"
+)
+
+// Writer encodes a JSON5 document value by value, in the same format as Marshal, for code that writes
+// its own types without building maps and slices of interface{} first, such as the MarshalJSON5
+// methods generated by cmd/json5gen. The first error is kept and reported by Err.
+type Writer struct {
+	e     *encodeState
+	sb    *strings.Builder // output of a Writer created by NewWriter
+	depth int              // depth of the value the Writer started at
+	stack []writerFrame    // open objects and arrays
+	err   error
+}
+
+// writerFrame is an object or array being written
+type writerFrame struct {
+	array bool
+	n     int // number of items written so far
+}
+
+// NewWriter returns a Writer collecting its output in memory, for Bytes
+func NewWriter() *Writer {
+	sb := &strings.Builder{}
+	return &Writer{e: &encodeState{w: sb}, sb: sb}
+}
+
+// writerTo is implemented by types that write themselves to a Writer, such as those generated by
+// cmd/json5gen; Marshal uses it so they are indented like the rest of the document
+type writerTo interface {
+	WriteJSON5(w *Writer)
+}
+
+// writeTo writes a writerTo at the given depth of e
+func (e *encodeState) writeTo(v writerTo, depth int) error {
+	w := &Writer{e: e, depth: depth}
+	v.WriteJSON5(w)
+	return w.err
+}
+
+// Bytes returns the output of a Writer created by NewWriter
+func (w *Writer) Bytes() []byte {
+	if w.sb == nil {
+		return nil
+	}
+	return []byte(w.sb.String())
+}
+
+// Err returns the first error met while writing
+func (w *Writer) Err() error {
+	return w.err
+}
+
+// BeginObject starts an object; its members are written with Key and a value
+func (w *Writer) BeginObject() {
+	w.beforeValue()
+	w.e.w.WriteByte('{')
+	w.stack = append(w.stack, writerFrame{})
+}
+
+// EndObject ends the current object
+func (w *Writer) EndObject() {
+	w.end('}')
+}
+
+// BeginArray starts an array; its items are written as values
+func (w *Writer) BeginArray() {
+	w.beforeValue()
+	w.e.w.WriteByte('[')
+	w.stack = append(w.stack, writerFrame{array: true})
+}
+
+// EndArray ends the current array
+func (w *Writer) EndArray() {
+	w.end(']')
+}
+
+// Key starts a member of the current object; its value is written next
+func (w *Writer) Key(key string) {
+	w.e.writeIndent(w.depth + len(w.stack))
+	w.e.w.WriteString(marshalKey(key))
+	w.e.w.WriteString(": ")
+}
+
+// String writes a string
+func (w *Writer) String(s string) {
+	w.beforeValue()
+	w.e.marshalString(s)
+	w.afterValue()
+}
+
+// Bool writes a boolean
+func (w *Writer) Bool(b bool) {
+	w.Value(b)
+}
+
+// Int writes a signed integer
+func (w *Writer) Int(n int64) {
+	w.Value(n)
+}
+
+// Uint writes an unsigned integer
+func (w *Writer) Uint(n uint64) {
+	w.Value(n)
+}
+
+// Float writes a float of bitSize bits, 32 or 64, in its shortest form for that size
+func (w *Writer) Float(f float64, bitSize int) {
+	if bitSize == 32 {
+		w.Value(float32(f))
+	} else {
+		w.Value(f)
+	}
+}
+
+// Null writes null
+func (w *Writer) Null() {
+	w.Value(nil)
+}
+
+// Value writes any value, as Marshal would
+func (w *Writer) Value(v interface{}) {
+	w.beforeValue()
+	if err := w.e.marshalValue(v, w.depth+len(w.stack)); err != nil && w.err == nil {
+		w.err = err
+	}
+	w.afterValue()
+}
+
+// SortKeys sorts the keys of a map in the order Marshal writes typed maps in: lexicographically, or with
+// MarshalOptions.KeyLess when the Writer is used by Marshal
+func (w *Writer) SortKeys(keys []string) []string {
+	return w.e.sortKeys(keys, true)
+}
+
+// beforeValue separates an array item from the previous one
+func (w *Writer) beforeValue() {
+	if len(w.stack) == 0 {
+		return
+	}
+	frame := &w.stack[len(w.stack)-1]
+	if frame.array {
+		if frame.n > 0 {
+			w.e.w.WriteString(", ")
+		}
+		w.e.writeIndent(w.depth + len(w.stack))
+	}
+	frame.n++
+}
+
+// afterValue terminates an object member
+func (w *Writer) afterValue() {
+	if len(w.stack) > 0 && !w.stack[len(w.stack)-1].array {
+		w.e.w.WriteByte(',')
+	}
+}
+
+// end closes the current object or array with the delimiter c
+func (w *Writer) end(c byte) {
+	if len(w.stack) == 0 {
+		return
+	}
+	w.stack = w.stack[:len(w.stack)-1]
+	w.e.writeIndent(w.depth + len(w.stack))
+	w.e.w.WriteByte(c)
+	w.afterValue()
+}
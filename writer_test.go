@@ -0,0 +1,72 @@
+package json5
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// point writes itself through a Writer, like the types generated by cmd/json5gen
+type point struct {
+	X, Y int
+}
+
+func (p point) WriteJSON5(w *Writer) {
+	w.BeginObject()
+	w.Key("x")
+	w.Int(int64(p.X))
+	w.Key("y")
+	w.Int(int64(p.Y))
+	w.EndObject()
+}
+
+func TestWriter(t *testing.T) {
+	w := NewWriter()
+	w.BeginObject()
+	w.Key("name")
+	w.String("svc")
+	w.Key("on")
+	w.Bool(true)
+	w.Key("port")
+	w.Uint(8080)
+	w.Key("ratio")
+	w.Float(0.1, 32)
+	w.Key("none")
+	w.Null()
+	w.Key("tags")
+	w.BeginArray()
+	w.String("a")
+	w.Int(-1)
+	w.BeginArray()
+	w.EndArray()
+	w.EndArray()
+	w.Key("a b")
+	w.Value(map[string]int{"b": 2, "a": 1})
+	w.EndObject()
+	assert.NoError(t, w.Err())
+	assert.Equal(t, "{\nname: \"svc\",\non: true,\nport: 8080,\nratio: 0.1,\nnone: null,\ntags: [\n\"a\", \n-1, \n[\n]\n],\n\"a b\": {\na: 1,\nb: 2,\n},\n}", string(w.Bytes()))
+}
+
+func TestWriterMatchesMarshal(t *testing.T) {
+	value := []interface{}{point{1, 2}, map[string]interface{}{"p": point{3, 4}}}
+	result, err := MarshalIndent(value, "  ")
+	assert.NoError(t, err)
+	expected, _ := MarshalIndent([]interface{}{
+		map[string]interface{}{"x": 1, "y": 2},
+		map[string]interface{}{"p": map[string]interface{}{"x": 3, "y": 4}},
+	}, "  ", MarshalOptions{SortKeys: true})
+	assert.Equal(t, expected, result)
+}
+
+func TestWriterErr(t *testing.T) {
+	w := NewWriter()
+	w.BeginArray()
+	w.Value(make(chan int))
+	w.Value(1)
+	w.EndArray()
+	assert.EqualError(t, w.Err(), "unsupported type: chan int")
+}
+
+func TestWriterSortKeys(t *testing.T) {
+	assert.Equal(t, []string{"a", "b", "c"}, NewWriter().SortKeys([]string{"c", "a", "b"}))
+}